@@ -9,3 +9,20 @@ func IsRedirect(code int) bool { return code >= 300 && code <= 399 }
 func IsClientError(code int) bool { return code >= 400 && code <= 499 }
 
 func IsServerError(code int) bool { return code >= 500 && code <= 599 }
+
+// StatusClass returns the class of the status code like "2xx", or "unknown" for codes out of 100-599.
+func StatusClass(code int) string {
+	switch {
+	case IsInfo(code):
+		return "1xx"
+	case IsSuccess(code):
+		return "2xx"
+	case IsRedirect(code):
+		return "3xx"
+	case IsClientError(code):
+		return "4xx"
+	case IsServerError(code):
+		return "5xx"
+	}
+	return "unknown"
+}
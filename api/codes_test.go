@@ -0,0 +1,22 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StatusClass(t *testing.T) {
+
+	for code, class := range map[int]string{
+		101: "1xx",
+		204: "2xx",
+		308: "3xx",
+		429: "4xx",
+		503: "5xx",
+		0:   "unknown",
+		600: "unknown",
+	} {
+		assert.Equal(t, class, StatusClass(code), code)
+	}
+}
@@ -0,0 +1,103 @@
+package colors
+
+import (
+	// builtin
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	escape = "\x1b["
+	reset  = "\x1b[0m"
+
+	// foreground codes
+	basicForeground = 31
+	bold            = "1"
+	underline       = "4"
+)
+
+type colorMode uint8
+
+const (
+	noColor colorMode = iota
+	basicColor
+	paletteColor
+	trueColor
+)
+
+// Color is a foreground color: one of the basic ColorCodes,
+// an index of the 256-color palette or a 24-bit RGB color.
+// The zero Color leaves the text color unchanged.
+type Color struct {
+	mode  colorMode
+	value uint32
+}
+
+// Basic returns one of the seven basic terminal colors.
+func Basic(code ColorCode) Color {
+	return Color{mode: basicColor, value: uint32(code)}
+}
+
+// Palette returns a color of the 256-color palette.
+func Palette(index uint8) Color {
+	return Color{mode: paletteColor, value: uint32(index)}
+}
+
+// RGB returns a truecolor color.
+func RGB(r, g, b uint8) Color {
+	return Color{mode: trueColor, value: uint32(r)<<16 | uint32(g)<<8 | uint32(b)}
+}
+
+// Hex parses a truecolor color written as "#rrggbb".
+func Hex(hex string) (Color, error) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(hex, "#")) != 6 {
+		return Color{}, fmt.Errorf("invalid hex color %q", hex)
+	}
+	return Color{mode: trueColor, value: uint32(value)}, nil
+}
+
+func (c Color) code() string {
+	switch c.mode {
+	case basicColor:
+		return strconv.Itoa(basicForeground + int(c.value))
+	case paletteColor:
+		return fmt.Sprintf("38;5;%d", c.value)
+	case trueColor:
+		return fmt.Sprintf("38;2;%d;%d;%d", c.value>>16&0xff, c.value>>8&0xff, c.value&0xff)
+	}
+	return ""
+}
+
+// Style describes how a text element is printed.
+type Style struct {
+	Color     Color
+	Bold      bool
+	Underline bool
+}
+
+// NewStyle returns a Style of the color.
+func NewStyle(color Color) Style {
+	return Style{Color: color}
+}
+
+// Sprint wraps the text into ANSI escape codes of the style.
+// Zero Style returns the text as is.
+func (s Style) Sprint(text string) string {
+	codes := make([]string, 0, 3)
+	if s.Bold {
+		codes = append(codes, bold)
+	}
+	if s.Underline {
+		codes = append(codes, underline)
+	}
+	if code := s.Color.code(); code != "" {
+		codes = append(codes, code)
+	}
+
+	if len(codes) == 0 {
+		return text
+	}
+	return escape + strings.Join(codes, ";") + "m" + text + reset
+}
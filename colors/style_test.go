@@ -0,0 +1,54 @@
+package colors
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StyleSprint(t *testing.T) {
+
+	t.Parallel()
+
+	hex, err := Hex("#ff8000")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		style  Style
+		expect string
+	}{
+		{"zero", Style{}, "text"},
+		{"basic", NewStyle(Basic(Red)), "\x1b[31mtext\x1b[0m"},
+		{"palette", NewStyle(Palette(208)), "\x1b[38;5;208mtext\x1b[0m"},
+		{"rgb", NewStyle(RGB(255, 128, 0)), "\x1b[38;2;255;128;0mtext\x1b[0m"},
+		{"hex", NewStyle(hex), "\x1b[38;2;255;128;0mtext\x1b[0m"},
+		{"bold underline", Style{Color: Basic(Cyan), Bold: true, Underline: true}, "\x1b[1;4;36mtext\x1b[0m"},
+		{"bold only", Style{Bold: true}, "\x1b[1mtext\x1b[0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.style.Sprint("text"))
+		})
+	}
+
+	_, err = Hex("#fff")
+	assert.Error(t, err)
+}
+
+func Test_Enabled(t *testing.T) {
+
+	assert.False(t, Enabled(&bytes.Buffer{}))
+
+	file, err := os.CreateTemp(t.TempDir(), "log")
+	require.NoError(t, err)
+	defer file.Close()
+	assert.False(t, Enabled(file))
+
+	t.Setenv(NoColorEnv, "1")
+	assert.False(t, Enabled(os.Stdout))
+}
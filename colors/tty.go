@@ -0,0 +1,35 @@
+package colors
+
+import (
+	// builtin
+	"io"
+	"os"
+
+	// pkg
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	// NoColorEnv disables colors when set to a non-empty value, see https://no-color.org.
+	NoColorEnv = "NO_COLOR"
+)
+
+// fder is implemented by *os.File and writers wrapping a terminal.
+type fder interface {
+	Fd() uintptr
+}
+
+// Enabled reports whether ANSI colors should be written to the writer:
+// NO_COLOR is not set and the writer is a terminal.
+func Enabled(writer io.Writer) bool {
+	if os.Getenv(NoColorEnv) != "" {
+		return false
+	}
+
+	file, ok := writer.(fder)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
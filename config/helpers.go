@@ -21,5 +21,5 @@ func (srv Server) Validate() error {
 
 type Credentials struct {
 	User     string
-	Password string
+	Password string `log:"redact"`
 }
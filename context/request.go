@@ -14,6 +14,10 @@ func (ctx *requestContext) Key() requestContextKey {
 	return requestContextKey{}
 }
 
+func (ctx *requestContext) RequestID() string {
+	return ctx.requestID
+}
+
 func WithRequestCtx(ctx context.Context, requestID string) context.Context {
 	return With(ctx, &requestContext{
 		requestID: requestID,
@@ -23,3 +27,12 @@ func WithRequestCtx(ctx context.Context, requestID string) context.Context {
 func RequestCtx(ctx context.Context) (*requestContext, bool) {
 	return From[*requestContext](ctx)
 }
+
+// RequestID returns the request ID stored with WithRequestCtx, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestCtx, ok := RequestCtx(ctx)
+	if !ok {
+		return ""
+	}
+	return requestCtx.RequestID()
+}
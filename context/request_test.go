@@ -16,4 +16,10 @@ func Test_RequestContext(t *testing.T) {
 	actualGC, ok := RequestCtx(ctx)
 	assert.True(t, ok)
 	assert.Equal(t, requestID, actualGC.requestID)
+	assert.Equal(t, requestID, actualGC.RequestID())
+}
+
+func Test_RequestID(t *testing.T) {
+	assert.Equal(t, "", RequestID(context.Background()))
+	assert.Equal(t, "requestID", RequestID(WithRequestCtx(context.Background(), "requestID")))
 }
@@ -0,0 +1,106 @@
+package context
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const (
+	traceParentVersion = "00"
+	traceIDLength      = 32
+	spanIDLength       = 16
+	sampledFlag        = 0x01
+)
+
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+type traceContextKey struct{}
+
+// traceContext holds the W3C trace context of a request, see https://www.w3.org/TR/trace-context.
+type traceContext struct {
+	traceID string
+	spanID  string
+	flags   byte
+}
+
+func (ctx *traceContext) Key() traceContextKey {
+	return traceContextKey{}
+}
+
+func (ctx *traceContext) TraceID() string {
+	return ctx.traceID
+}
+
+func (ctx *traceContext) SpanID() string {
+	return ctx.spanID
+}
+
+func (ctx *traceContext) Sampled() bool {
+	return ctx.flags&sampledFlag != 0
+}
+
+// TraceParent formats the trace context as a traceparent header value.
+func (ctx *traceContext) TraceParent() string {
+	return strings.Join([]string{traceParentVersion, ctx.traceID, ctx.spanID, hex.EncodeToString([]byte{ctx.flags})}, "-")
+}
+
+// WithTraceParent parses the W3C traceparent value and stores it in the context.
+func WithTraceParent(ctx context.Context, traceParent string) (context.Context, error) {
+	traceCtx, err := parseTraceParent(traceParent)
+	if err != nil {
+		return ctx, err
+	}
+	return With(ctx, traceCtx), nil
+}
+
+func TraceCtx(ctx context.Context) (*traceContext, bool) {
+	return From[*traceContext](ctx)
+}
+
+// parseTraceParent parses "version-traceid-spanid-flags".
+// Versions other than 00 are accepted as long as the first four fields are valid.
+func parseTraceParent(value string) (*traceContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return nil, ErrInvalidTraceParent
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	if !isHex(version, 2) || version == "ff" || (version == traceParentVersion && len(parts) != 4) {
+		return nil, ErrInvalidTraceParent
+	}
+
+	if !isHex(traceID, traceIDLength) || isZero(traceID) ||
+		!isHex(spanID, spanIDLength) || isZero(spanID) ||
+		!isHex(flags, 2) {
+		return nil, ErrInvalidTraceParent
+	}
+
+	flagsByte, _ := hex.DecodeString(flags)
+
+	return &traceContext{
+		traceID: traceID,
+		spanID:  spanID,
+		flags:   flagsByte[0],
+	}, nil
+}
+
+// isHex reports whether value consists of length lowercase hex digits.
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(value string) bool {
+	return strings.Trim(value, "0") == ""
+}
@@ -0,0 +1,53 @@
+package context
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TraceContext(t *testing.T) {
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	ctx, err := WithTraceParent(context.Background(), traceParent)
+	require.NoError(t, err)
+
+	traceCtx, ok := TraceCtx(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceCtx.TraceID())
+	assert.Equal(t, "00f067aa0ba902b7", traceCtx.SpanID())
+	assert.True(t, traceCtx.Sampled())
+	assert.Equal(t, traceParent, traceCtx.TraceParent())
+}
+
+func Test_TraceParentInvalid(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			ctx, err := WithTraceParent(context.Background(), tt)
+			assert.ErrorIs(t, err, ErrInvalidTraceParent)
+			_, ok := TraceCtx(ctx)
+			assert.False(t, ok)
+		})
+	}
+
+	_, err := WithTraceParent(context.Background(), "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,224 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultQueueSize = 1024
+	defaultBatchSize = 64
+)
+
+var ErrWriterClosed = errors.New("log: writer is closed")
+
+// OverflowPolicy decides what AsyncWriter does with a record when its queue is full.
+type OverflowPolicy uint8
+
+const (
+	// Block makes the writing goroutine wait for a free slot.
+	Block OverflowPolicy = iota
+	// DropNewest discards the record being written.
+	DropNewest
+	// DropOldest discards the oldest queued record to make room for the new one.
+	DropOldest
+)
+
+// AsyncWriter queues records in a bounded ring buffer and writes them
+// to the underlying writer in batches on a background goroutine.
+// Every Write call is treated as one record, which is how Handler writes.
+// Queued records are lost unless it is flushed or closed before the process exits,
+// Fatal flushes it when it is the writer of a Handler.
+type AsyncWriter struct {
+	writer io.Writer
+
+	mutex    sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	idle     *sync.Cond
+
+	// ring buffer of queued records
+	queue [][]byte
+	head  int
+	size  int
+
+	batchSize int
+	policy    OverflowPolicy
+
+	inflight bool
+	closed   bool
+	err      error
+	done     chan struct{}
+
+	dropped atomic.Uint64
+	metrics Metrics
+}
+
+// The signature of the function for setting AsyncWriter parameters
+type AsyncOption func(*AsyncWriter)
+
+// NewAsyncWriter starts a background goroutine writing to writer.
+// Close must be called to flush queued records and stop it.
+func NewAsyncWriter(writer io.Writer, opts ...AsyncOption) *AsyncWriter {
+	w := &AsyncWriter{
+		writer:    writer,
+		queue:     make([][]byte, defaultQueueSize),
+		batchSize: defaultBatchSize,
+		done:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.notEmpty = sync.NewCond(&w.mutex)
+	w.notFull = sync.NewCond(&w.mutex)
+	w.idle = sync.NewCond(&w.mutex)
+
+	go w.run()
+
+	return w
+}
+
+// WithQueueSize sets the maximum number of records waiting to be written.
+func WithQueueSize(size int) AsyncOption {
+	return func(w *AsyncWriter) {
+		if size > 0 {
+			w.queue = make([][]byte, size)
+		}
+	}
+}
+
+// WithBatchSize sets the maximum number of records written at once.
+func WithBatchSize(size int) AsyncOption {
+	return func(w *AsyncWriter) {
+		if size > 0 {
+			w.batchSize = size
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens to records written while the queue is full.
+func WithOverflowPolicy(policy OverflowPolicy) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.policy = policy
+	}
+}
+
+// Write queues a copy of p. It never returns the underlying writer's errors,
+// the last of them is returned by Flush and Close.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+
+	record := append([]byte(nil), p...)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.policy == Block {
+		for w.size == len(w.queue) && !w.closed {
+			w.notFull.Wait()
+		}
+	}
+
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+
+	if w.size == len(w.queue) {
+		w.dropped.Add(1)
+		if w.metrics != nil {
+			w.metrics.Dropped()
+		}
+		if w.policy == DropNewest {
+			return len(p), nil
+		}
+		w.queue[w.head] = nil
+		w.head = (w.head + 1) % len(w.queue)
+		w.size--
+	}
+
+	w.queue[(w.head+w.size)%len(w.queue)] = record
+	w.size++
+	w.notEmpty.Signal()
+
+	return len(p), nil
+}
+
+// Dropped returns the number of records discarded because the queue was full.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush blocks until every queued record has been written.
+func (w *AsyncWriter) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for w.size > 0 || w.inflight {
+		w.idle.Wait()
+	}
+
+	return w.err
+}
+
+// Close writes the queued records, stops the background goroutine
+// and rejects further writes. The underlying writer is not closed.
+func (w *AsyncWriter) Close() error {
+	w.mutex.Lock()
+	if !w.closed {
+		w.closed = true
+		w.notEmpty.Broadcast()
+		w.notFull.Broadcast()
+	}
+	w.mutex.Unlock()
+
+	<-w.done
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.err
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+
+	var batch []byte
+
+	for {
+		w.mutex.Lock()
+		for w.size == 0 && !w.closed {
+			w.notEmpty.Wait()
+		}
+
+		if w.size == 0 {
+			w.mutex.Unlock()
+			return
+		}
+
+		batch = batch[:0]
+		for n := 0; n < w.batchSize && w.size > 0; n++ {
+			batch = append(batch, w.queue[w.head]...)
+			w.queue[w.head] = nil
+			w.head = (w.head + 1) % len(w.queue)
+			w.size--
+		}
+
+		w.inflight = true
+		w.notFull.Broadcast()
+		w.mutex.Unlock()
+
+		_, err := w.writer.Write(batch)
+
+		w.mutex.Lock()
+		if err != nil {
+			w.err = err
+		}
+		w.inflight = false
+		if w.size == 0 {
+			w.idle.Broadcast()
+		}
+		w.mutex.Unlock()
+	}
+}
@@ -0,0 +1,158 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gateWriter blocks every Write until the gate is opened.
+type gateWriter struct {
+	mutex   sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{
+		started: make(chan struct{}),
+		gate:    make(chan struct{}),
+	}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.gate
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.buf.String()
+}
+
+func Test_AsyncWriter(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	w := NewAsyncWriter(buf, WithBatchSize(3))
+
+	for i := range 10 {
+		_, err := fmt.Fprintf(w, "%d\n", i)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n", buf.String())
+
+	require.NoError(t, w.Close())
+	_, err := w.Write([]byte("closed"))
+	assert.ErrorIs(t, err, ErrWriterClosed)
+	assert.Zero(t, w.Dropped())
+}
+
+func Test_AsyncWriterOverflow(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		expect string
+	}{
+		{"drop newest", DropNewest, "0\n1\n2\n"},
+		{"drop oldest", DropOldest, "0\n3\n4\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			gw := newGateWriter()
+			w := NewAsyncWriter(gw, WithQueueSize(2), WithBatchSize(1), WithOverflowPolicy(tt.policy))
+
+			// the first record is taken by the background goroutine
+			// and blocks it, the rest stay in the queue
+			fmt.Fprintln(w, 0)
+			<-gw.started
+			for i := 1; i < 5; i++ {
+				fmt.Fprintln(w, i)
+			}
+
+			close(gw.gate)
+			require.NoError(t, w.Close())
+
+			assert.Equal(t, tt.expect, gw.String())
+			assert.EqualValues(t, 2, w.Dropped())
+		})
+	}
+}
+
+func Test_AsyncWriterBlock(t *testing.T) {
+
+	gw := newGateWriter()
+	w := NewAsyncWriter(gw, WithQueueSize(1), WithBatchSize(1))
+
+	fmt.Fprintln(w, 0)
+	<-gw.started
+	fmt.Fprintln(w, 1)
+
+	written := make(chan struct{})
+	go func() {
+		fmt.Fprintln(w, 2)
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("write must block while the queue is full")
+	default:
+	}
+
+	close(gw.gate)
+	<-written
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, "0\n1\n2\n", gw.String())
+	assert.Zero(t, w.Dropped())
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, io.ErrShortWrite
+}
+
+func Test_AsyncWriterError(t *testing.T) {
+
+	w := NewAsyncWriter(errWriter{})
+
+	_, err := w.Write([]byte("record"))
+	require.NoError(t, err)
+
+	assert.True(t, errors.Is(w.Flush(), io.ErrShortWrite))
+	assert.True(t, errors.Is(w.Close(), io.ErrShortWrite))
+}
+
+func Test_AsyncWriterHandler(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	w := NewAsyncWriter(buf)
+	logger := slog.New(NewHandler(WithWriter(w), WithJSONOutput()))
+
+	for i := range 100 {
+		logger.Info("record", slog.Int("i", i))
+	}
+
+	require.NoError(t, w.Close())
+	assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 100)
+}
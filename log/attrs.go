@@ -16,10 +16,13 @@ const (
 	AttrUserID       = "user_id" // Assuming User struct has field "ID"
 	AttrAppID        = "app_id"  // Assuming App struct has field
 	AttrAppComponent = "app_component"
+	AttrRequestID    = "request_id"
 )
 
+// Error returns the error attribute. It renders the message, the concrete type,
+// the chain of wrapped errors and the gRPC status code, and is safe for nil errors.
 func Error(err error) slog.Attr {
-	return slog.String(AttrError, err.Error())
+	return slog.Any(AttrError, errorValue{err: err})
 }
 
 func Operation(op string) slog.Attr {
@@ -41,3 +44,7 @@ func AppID(appID string) slog.Attr {
 func AppComponent(component string) slog.Attr {
 	return slog.String(AttrAppComponent, component)
 }
+
+func RequestID(requestID string) slog.Attr {
+	return slog.String(AttrRequestID, requestID)
+}
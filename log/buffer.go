@@ -0,0 +1,140 @@
+package log
+
+import (
+	// builtin
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	// internal
+	context_helper "github.com/vishenosik/web/context"
+)
+
+// maxBufferedRecords limits the memory a request can hold,
+// the oldest records are dropped once it is reached.
+const maxBufferedRecords = 1024
+
+type logBufferKey struct{}
+
+// LogBuffer holds records of a request until it is known whether they are needed.
+type LogBuffer struct {
+	mutex   sync.Mutex
+	entries []bufferedRecord
+	dropped uint64
+}
+
+type bufferedRecord struct {
+	// the handler derived with attributes and groups of the logger
+	handler slog.Handler
+	// the context the record was logged with, it carries the request ID and trace
+	ctx context.Context
+	rec slog.Record
+}
+
+func (buffer *LogBuffer) Key() logBufferKey {
+	return logBufferKey{}
+}
+
+// WithLogBuffer returns a context holding a new buffer for records of BufferHandler.
+func WithLogBuffer(ctx context.Context) (context.Context, *LogBuffer) {
+	buffer := &LogBuffer{}
+	return context_helper.With(ctx, buffer), buffer
+}
+
+// LogBufferFrom returns the buffer stored with WithLogBuffer.
+func LogBufferFrom(ctx context.Context) (*LogBuffer, bool) {
+	return context_helper.From[*LogBuffer](ctx)
+}
+
+// Len returns the number of records held.
+func (buffer *LogBuffer) Len() int {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return len(buffer.entries)
+}
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (buffer *LogBuffer) Dropped() uint64 {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.dropped
+}
+
+// Flush writes the held records in the order they were logged, with the contexts
+// they were logged with, and empties the buffer.
+func (buffer *LogBuffer) Flush() error {
+	buffer.mutex.Lock()
+	entries := buffer.entries
+	buffer.entries = nil
+	buffer.mutex.Unlock()
+
+	var err error
+	for _, entry := range entries {
+		err = errors.Join(err, entry.handler.Handle(entry.ctx, entry.rec))
+	}
+	return err
+}
+
+// Discard drops the held records.
+func (buffer *LogBuffer) Discard() {
+	buffer.mutex.Lock()
+	buffer.entries = nil
+	buffer.mutex.Unlock()
+}
+
+func (buffer *LogBuffer) add(ctx context.Context, handler slog.Handler, rec slog.Record) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	if len(buffer.entries) >= maxBufferedRecords {
+		buffer.entries = buffer.entries[1:]
+		buffer.dropped++
+	}
+	buffer.entries = append(buffer.entries, bufferedRecord{handler: handler, ctx: ctx, rec: rec.Clone()})
+}
+
+// BufferHandler holds records below the level in the LogBuffer of the context
+// they are logged with, records at or above it and records logged without
+// a buffer are passed to the next handler as is.
+// Buffered records are written even if the next handler is not enabled for their level,
+// so that a failed request gets its debug records.
+type BufferHandler struct {
+	next  slog.Handler
+	level slog.Leveler
+}
+
+// NewBufferHandler wraps next so that records below the level are buffered per request.
+func NewBufferHandler(next slog.Handler, level slog.Leveler) *BufferHandler {
+	return &BufferHandler{
+		next:  next,
+		level: level,
+	}
+}
+
+func (h *BufferHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level < h.level.Level() {
+		if _, ok := LogBufferFrom(ctx); ok {
+			return true
+		}
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *BufferHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if rec.Level < h.level.Level() {
+		if buffer, ok := LogBufferFrom(ctx); ok {
+			buffer.add(ctx, h.next, rec)
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, rec)
+}
+
+func (h *BufferHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &BufferHandler{next: h.next.WithAttrs(attrs), level: h.level}
+}
+
+func (h *BufferHandler) WithGroup(name string) slog.Handler {
+	return &BufferHandler{next: h.next.WithGroup(name), level: h.level}
+}
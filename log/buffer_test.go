@@ -0,0 +1,63 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_BufferHandler(t *testing.T) {
+
+	records := logtest.NewHandler(slog.LevelInfo)
+	logger := slog.New(NewBufferHandler(records, slog.LevelWarn)).With(slog.String("service", "web"))
+
+	ctx, buffer := WithLogBuffer(context.Background())
+
+	assert.True(t, logger.Enabled(ctx, slog.LevelDebug))
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	logger.DebugContext(ctx, "cache miss")
+	logger.InfoContext(ctx, "query done")
+	logger.ErrorContext(ctx, "query failed")
+	logger.Info("outside of a request")
+
+	logtest.AssertMessages(t, records, "query failed", "outside of a request")
+	assert.Equal(t, 2, buffer.Len())
+
+	assert.NoError(t, buffer.Flush())
+	assert.Equal(t, 0, buffer.Len())
+
+	logtest.AssertMessages(t, records, "query failed", "outside of a request", "cache miss", "query done")
+	logtest.AssertLogged(t, records, slog.LevelDebug, "cache miss", slog.String("service", "web"))
+}
+
+func Test_LogBufferDiscard(t *testing.T) {
+
+	records := logtest.NewHandler(slog.LevelDebug)
+	logger := slog.New(NewBufferHandler(records, slog.LevelWarn))
+
+	ctx, buffer := WithLogBuffer(context.Background())
+	logger.InfoContext(ctx, "dropped")
+	buffer.Discard()
+
+	assert.NoError(t, buffer.Flush())
+	assert.Empty(t, records.Records())
+}
+
+func Test_LogBufferLimit(t *testing.T) {
+
+	records := logtest.NewHandler(slog.LevelDebug)
+	logger := slog.New(NewBufferHandler(records, slog.LevelWarn))
+
+	ctx, buffer := WithLogBuffer(context.Background())
+	for range maxBufferedRecords + 2 {
+		logger.InfoContext(ctx, "record")
+	}
+
+	assert.Equal(t, maxBufferedRecords, buffer.Len())
+	assert.Equal(t, uint64(2), buffer.Dropped())
+}
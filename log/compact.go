@@ -0,0 +1,129 @@
+package log
+
+import (
+	// builtin
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultValueWidth = 64
+	truncated         = "…"
+	expandIndent      = "    "
+)
+
+// WithCompactOutput prints attributes as key=value pairs on the message line.
+// Errors and structs are expanded onto the following lines.
+func WithCompactOutput() HandlerOption {
+	return func(h *Handler) {
+		h.compact = true
+	}
+}
+
+// WithMaxValueWidth truncates values of the compact output longer than width runes.
+// Zero or negative width turns truncation off.
+func WithMaxValueWidth(width int) HandlerOption {
+	return func(h *Handler) {
+		h.valueWidth = width
+	}
+}
+
+// expanded is an attribute printed on its own lines below the message.
+type expanded struct {
+	key   string
+	value string
+}
+
+// writeCompact writes attributes after the message and terminates the record with a new line.
+func (h *Handler) writeCompact(builder *strings.Builder, attrs attrs) {
+	var blocks []expanded
+	h.writeCompactAttrs(builder, attrs, "", &blocks)
+	builder.WriteString("\n")
+
+	for _, block := range blocks {
+		builder.WriteString(fmt.Sprintf("%s%s:\n", expandIndent, block.key))
+		for _, line := range strings.Split(block.value, "\n") {
+			builder.WriteString(fmt.Sprintf("%s%s%s\n", expandIndent, expandIndent, line))
+		}
+	}
+}
+
+func (h *Handler) writeCompactAttrs(builder *strings.Builder, items attrs, prefix string, blocks *[]expanded) {
+	for _, item := range items {
+		key := prefix + item.key
+
+		if group, ok := item.value.(*attrs); ok {
+			h.writeCompactAttrs(builder, *group, key+".", blocks)
+			continue
+		}
+
+		if err, ok := item.value.(errorString); ok {
+			*blocks = append(*blocks, expanded{key: h.paint(h.theme.Error, key), value: string(err)})
+			continue
+		}
+
+		if isStruct(item.value) {
+			data, err := json.MarshalIndent(item.value, "", jsonIndent)
+			if err == nil {
+				*blocks = append(*blocks, expanded{key: h.paint(h.theme.Key, key), value: string(data)})
+				continue
+			}
+		}
+
+		builder.WriteString(fmt.Sprintf(" %s=%s", h.paint(h.theme.Key, key), h.compactValue(item.value)))
+	}
+}
+
+// compactValue formats a value for the message line, colored by its type.
+func (h *Handler) compactValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return h.paint(h.theme.String, quote(h.truncate(v)))
+
+	case int64, uint64, float64:
+		return h.paint(h.theme.Number, fmt.Sprint(v))
+
+	case bool:
+		return h.paint(h.theme.Bool, strconv.FormatBool(v))
+
+	case nil:
+		return h.paint(h.theme.Key, "nil")
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		data = []byte(fmt.Sprint(value))
+	}
+	return h.truncate(string(data))
+}
+
+func (h *Handler) truncate(value string) string {
+	if h.valueWidth <= 0 || utf8.RuneCountInString(value) <= h.valueWidth {
+		return value
+	}
+	return string([]rune(value)[:h.valueWidth]) + truncated
+}
+
+// quote quotes strings which would be ambiguous in key=value form.
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\t\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// isStruct reports whether the value is a struct or a map, possibly behind pointers.
+func isStruct(value any) bool {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Struct || rv.Kind() == reflect.Map
+}
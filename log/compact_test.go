@@ -0,0 +1,62 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CompactOutput(t *testing.T) {
+
+	type user struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithCompactOutput(), WithMaxValueWidth(8))).
+		With(AppComponent("api")).
+		WithGroup("request")
+
+	logger.Info("request failed",
+		slog.String("method", "GET"),
+		slog.String("path", "/api/v1/users/list"),
+		slog.String("query", "a b"),
+		slog.Int("code", 500),
+		slog.Bool("retry", false),
+		slog.Any("user", user{ID: 1, Name: "john"}),
+		slog.Any("err", errors.New("connection refused")),
+	)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 8)
+
+	assert.True(t, strings.HasSuffix(lines[0],
+		`[api] INFO: request failed request.method=GET request.path=/api/v1/… request.query="a b" request.code=500 request.retry=false`,
+	))
+	assert.Equal(t, []string{
+		`    request.user:`,
+		`        {`,
+		`          "id": 1,`,
+		`          "name": "john"`,
+		`        }`,
+		`    request.err:`,
+		`        connection refused`,
+	}, lines[1:])
+}
+
+func Test_CompactOutputNoAttrs(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithCompactOutput()))
+
+	logger.Warn("empty")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.True(t, strings.HasSuffix(buf.String(), "empty\n"))
+}
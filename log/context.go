@@ -0,0 +1,72 @@
+package log
+
+import (
+	// builtin
+	"context"
+	"log/slog"
+
+	// internal
+	context_helper "github.com/vishenosik/web/context"
+)
+
+// ContextExtractor returns request-scoped attributes stored in the context a record is logged with.
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+// FromContextValue makes a ContextExtractor for a value stored with context.With.
+// The extractor returns no attributes if the context has no such value.
+//
+// Example:
+//
+//	extractor := FromContextValue(func(user *userContext) []slog.Attr {
+//	    return []slog.Attr{UserID(user.ID)}
+//	})
+func FromContextValue[_type context_helper.ContextValue[keyType], keyType context_helper.Key](
+	attrs func(_type) []slog.Attr,
+) ContextExtractor {
+	return func(ctx context.Context) []slog.Attr {
+		value, ok := context_helper.From[_type](ctx)
+		if !ok {
+			return nil
+		}
+		return attrs(value)
+	}
+}
+
+// RequestIDExtractor adds the request ID stored with context.WithRequestCtx.
+// Handler uses it by default.
+func RequestIDExtractor(ctx context.Context) []slog.Attr {
+	requestID := context_helper.RequestID(ctx)
+	if requestID == "" {
+		return nil
+	}
+	return []slog.Attr{RequestID(requestID)}
+}
+
+// WithContextExtractors adds extractors which attributes are appended to every record.
+func WithContextExtractors(extractors ...ContextExtractor) HandlerOption {
+	return func(h *Handler) {
+		h.extractors = append(h.extractors, extractors...)
+	}
+}
+
+// WithoutContextExtractors removes all extractors, including the default ones
+// and the trace extractor.
+func WithoutContextExtractors() HandlerOption {
+	return func(h *Handler) {
+		h.extractors = nil
+		h.trace = nil
+	}
+}
+
+// contextAttrs returns attributes of all extractors and the trace.
+func (h *Handler) contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil || (len(h.extractors) == 0 && h.trace == nil) {
+		return nil
+	}
+
+	attrs := TraceAttrs(ctx, h.trace)
+	for _, extractor := range h.extractors {
+		attrs = append(attrs, extractor(ctx)...)
+	}
+	return attrs
+}
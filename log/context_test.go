@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	context_helper "github.com/vishenosik/web/context"
+)
+
+type testUserKey struct{}
+
+type testUser struct {
+	id string
+}
+
+func (user *testUser) Key() testUserKey {
+	return testUserKey{}
+}
+
+func Test_ContextExtractors(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(
+		WithWriter(buf),
+		WithJSONOutput(),
+		WithContextExtractors(FromContextValue(func(user *testUser) []slog.Attr {
+			return []slog.Attr{UserID(user.id)}
+		})),
+	))
+
+	ctx := context_helper.WithRequestCtx(context.Background(), "request-1")
+	ctx = context_helper.With(ctx, &testUser{id: "user-1"})
+
+	logger.InfoContext(ctx, "msg")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "request-1", record[AttrRequestID])
+	assert.Equal(t, "user-1", record[AttrUserID])
+}
+
+func Test_ContextExtractorsEmpty(t *testing.T) {
+
+	h := NewHandler()
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+
+	assert.Empty(t, h.contextAttrs(context.Background()))
+
+	ctx := context_helper.WithRequestCtx(context.Background(), "request-1")
+	assert.Equal(t, attrs{{AttrRequestID, "request-1"}}, h.computeAttrs(rec, h.contextAttrs(ctx)...))
+
+	h = NewHandler(WithoutContextExtractors())
+	assert.Empty(t, h.contextAttrs(ctx))
+}
+
+func Test_ContextAttrsInGroup(t *testing.T) {
+
+	ctx, err := context_helper.WithTraceParent(
+		context_helper.WithRequestCtx(context.Background(), "request-1"),
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	)
+	require.NoError(t, err)
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput(), WithStackTrace(slog.LevelError))).
+			With(slog.String("service", "web")).
+			WithGroup("http")
+
+		logger.ErrorContext(ctx, "msg", slog.Int("code", 500))
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "request-1", record[AttrRequestID])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record[AttrTraceID])
+		assert.Equal(t, "00f067aa0ba902b7", record[AttrSpanID])
+		assert.NotEmpty(t, record[AttrStack])
+		assert.Equal(t, "web", record["service"])
+		assert.Equal(t, map[string]any{"code": float64(500)}, record["http"])
+	})
+
+	t.Run("pretty", func(t *testing.T) {
+		h := NewHandler(WithStackTrace(slog.LevelError)).WithGroup("http").(*Handler)
+		rec := slog.NewRecord(time.Now(), slog.LevelError, "msg", 0)
+		rec.AddAttrs(slog.Int("code", 500))
+
+		top := append(h.contextAttrs(ctx), h.stackAttrs(rec)...)
+		computed := h.computeAttrs(rec, top...)
+
+		var keys []string
+		for _, item := range computed {
+			keys = append(keys, item.key)
+		}
+		assert.Equal(t, []string{"http", AttrTraceID, AttrSpanID, AttrRequestID, AttrStack}, keys)
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		slog.New(NewHandler(WithWriter(buf), WithCompactOutput())).WithGroup("http").
+			InfoContext(ctx, "msg", slog.Int("code", 200))
+
+		assert.Contains(t, buf.String(), " http.code=200 ")
+		assert.Contains(t, buf.String(), " request_id=request-1")
+		assert.NotContains(t, buf.String(), "http.request_id")
+	})
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	// builtin
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	// pkg
+	"google.golang.org/grpc/status"
+)
+
+const (
+	errorMessageKey = "msg"
+	errorTypeKey    = "type"
+	errorCodeKey    = "code"
+	errorChainKey   = "chain"
+	nilError        = "<nil>"
+)
+
+// errorValue renders an error as a group of its message, concrete type,
+// gRPC status code, chain of wrapped errors and optionally the stack.
+type errorValue struct {
+	err   error
+	stack bool
+}
+
+// errorLink is an error of the chain. Errors joined with errors.Join
+// or wrapping several errors have a chain per wrapped error.
+type errorLink struct {
+	Message  string        `json:"msg" yaml:"msg"`
+	Type     string        `json:"type" yaml:"type"`
+	Branches [][]errorLink `json:"branches,omitempty" yaml:"branches,omitempty"`
+}
+
+// ErrorWithStack is Error with the stack stored by github.com/pkg/errors, if the error has one.
+func ErrorWithStack(err error) slog.Attr {
+	return slog.Any(AttrError, errorValue{err: err, stack: true})
+}
+
+func (e errorValue) LogValue() slog.Value {
+	if e.err == nil {
+		return slog.StringValue(nilError)
+	}
+
+	attrs := []slog.Attr{
+		slog.String(errorMessageKey, e.err.Error()),
+		slog.String(errorTypeKey, errorType(e.err)),
+	}
+
+	if code, ok := grpcCode(e.err); ok {
+		attrs = append(attrs, slog.String(errorCodeKey, code))
+	}
+
+	if chain := errorChain(e.err); len(chain) > 1 || len(chain) == 1 && len(chain[0].Branches) > 0 {
+		attrs = append(attrs, slog.Any(errorChainKey, chain))
+	}
+
+	if e.stack {
+		if stack := ErrorStack(e.err); len(stack) > 0 {
+			attrs = append(attrs, slog.Any(AttrStack, formatStack(stack)))
+		}
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// String returns the error message, so that the attribute reads well where it is not resolved.
+func (e errorValue) String() string {
+	if e.err == nil {
+		return nilError
+	}
+	return e.err.Error()
+}
+
+// text formats the error for the compact output, one line per error of the chain.
+func (e errorValue) text() string {
+	if e.err == nil {
+		return nilError
+	}
+
+	builder := &strings.Builder{}
+	writeChain(builder, errorChain(e.err), "")
+
+	if code, ok := grpcCode(e.err); ok {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", errorCodeKey, code))
+	}
+
+	if e.stack {
+		for _, frame := range formatStack(ErrorStack(e.err)) {
+			builder.WriteString(frame + "\n")
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func writeChain(builder *strings.Builder, chain []errorLink, indent string) {
+	for _, link := range chain {
+		builder.WriteString(fmt.Sprintf("%s%s (%s)\n", indent, link.Message, link.Type))
+		for _, branch := range link.Branches {
+			writeChain(builder, branch, indent+"  ")
+		}
+	}
+}
+
+// errorChain unwraps the error down to its root cause.
+func errorChain(err error) []errorLink {
+	var chain []errorLink
+	for err != nil {
+		link := errorLink{Message: err.Error(), Type: errorType(err)}
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, branch := range wrapped.Unwrap() {
+				link.Branches = append(link.Branches, errorChain(branch))
+			}
+			err = nil
+		default:
+			err = errors.Unwrap(err)
+		}
+
+		chain = append(chain, link)
+	}
+	return chain
+}
+
+func errorType(err error) string {
+	return fmt.Sprintf("%T", err)
+}
+
+// grpcCode returns the code of a gRPC status error in the chain.
+func grpcCode(err error) (string, bool) {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return "", false
+	}
+	return grpcErr.GRPCStatus().Code().String(), true
+}
@@ -0,0 +1,112 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	pkg_errors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_ErrorNil(t *testing.T) {
+
+	attr := Error(nil)
+	assert.Equal(t, AttrError, attr.Key)
+	assert.Equal(t, "<nil>", attr.Value.String())
+	assert.Equal(t, "<nil>", attr.Value.Resolve().String())
+
+	buf := &bytes.Buffer{}
+	slog.New(NewHandler(WithWriter(buf), WithCompactOutput())).Error("failed", attr)
+	assert.Contains(t, buf.String(), "<nil>")
+}
+
+func Test_ErrorValue(t *testing.T) {
+
+	err := fmt.Errorf("get user: %w", errors.Join(
+		fmt.Errorf("query: %w", io.EOF),
+		status.Error(codes.NotFound, "no user"),
+	))
+
+	buf := &bytes.Buffer{}
+	slog.New(NewHandler(WithWriter(buf), WithJSONOutput())).Error("failed", Error(err))
+
+	var line struct {
+		Err struct {
+			Message string      `json:"msg"`
+			Type    string      `json:"type"`
+			Code    string      `json:"code"`
+			Chain   []errorLink `json:"chain"`
+		} `json:"err"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+
+	assert.Equal(t, err.Error(), line.Err.Message)
+	assert.Equal(t, "*fmt.wrapError", line.Err.Type)
+	assert.Equal(t, "NotFound", line.Err.Code)
+
+	require.Len(t, line.Err.Chain, 2)
+	assert.Equal(t, "*errors.joinError", line.Err.Chain[1].Type)
+	assert.Equal(t, [][]errorLink{
+		{
+			{Message: "query: EOF", Type: "*fmt.wrapError"},
+			{Message: "EOF", Type: "*errors.errorString"},
+		},
+		{
+			{Message: "rpc error: code = NotFound desc = no user", Type: "*status.Error"},
+		},
+	}, line.Err.Chain[1].Branches)
+}
+
+func Test_ErrorValueNoChain(t *testing.T) {
+
+	value := Error(io.EOF).Value.Resolve()
+	require.Equal(t, slog.KindGroup, value.Kind())
+
+	var keys []string
+	for _, attr := range value.Group() {
+		keys = append(keys, attr.Key)
+	}
+	assert.Equal(t, []string{"msg", "type"}, keys)
+}
+
+func Test_ErrorWithStack(t *testing.T) {
+
+	value := ErrorWithStack(pkg_errors.Wrap(newStackError(), "wrapped")).Value.Resolve()
+
+	var stack []string
+	for _, attr := range value.Group() {
+		if attr.Key == AttrStack {
+			stack = attr.Value.Any().([]string)
+		}
+	}
+	require.NotEmpty(t, stack)
+	assert.Contains(t, stack[0], "log.newStackError")
+
+	value = Error(pkg_errors.Wrap(newStackError(), "wrapped")).Value.Resolve()
+	for _, attr := range value.Group() {
+		assert.NotEqual(t, AttrStack, attr.Key)
+	}
+}
+
+func Test_ErrorCompactOutput(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	slog.New(NewHandler(WithWriter(buf), WithCompactOutput())).
+		Error("failed", Error(fmt.Errorf("read: %w", io.EOF)))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, []string{
+		`    err:`,
+		`        read: EOF (*fmt.wrapError)`,
+		`        EOF (*errors.errorString)`,
+	}, lines[1:])
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	// builtin
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	// pkg
+	"google.golang.org/grpc/grpclog"
+)
+
+// grpcLogger adapts slog.Logger to grpclog.LoggerV2.
+type grpcLogger struct {
+	logger    *slog.Logger
+	verbosity int
+}
+
+// NewGRPCLogger returns a grpclog.LoggerV2 writing to the logger,
+// so that grpc internals log through the same handler:
+//
+//	grpclog.SetLoggerV2(log.NewGRPCLogger(logger.With(log.AppComponent("grpc")), 0))
+//
+// V reports true for levels up to verbosity. Fatal logs at LevelFatal and exits.
+func NewGRPCLogger(logger *slog.Logger, verbosity int) grpclog.LoggerV2 {
+	return &grpcLogger{
+		logger:    logger,
+		verbosity: verbosity,
+	}
+}
+
+func (gl *grpcLogger) Info(args ...any) {
+	gl.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (gl *grpcLogger) Infoln(args ...any) {
+	gl.log(slog.LevelInfo, sprintln(args...))
+}
+
+func (gl *grpcLogger) Infof(format string, args ...any) {
+	gl.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (gl *grpcLogger) Warning(args ...any) {
+	gl.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (gl *grpcLogger) Warningln(args ...any) {
+	gl.log(slog.LevelWarn, sprintln(args...))
+}
+
+func (gl *grpcLogger) Warningf(format string, args ...any) {
+	gl.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (gl *grpcLogger) Error(args ...any) {
+	gl.log(slog.LevelError, fmt.Sprint(args...))
+}
+
+func (gl *grpcLogger) Errorln(args ...any) {
+	gl.log(slog.LevelError, sprintln(args...))
+}
+
+func (gl *grpcLogger) Errorf(format string, args ...any) {
+	gl.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+func (gl *grpcLogger) Fatal(args ...any) {
+	fatal(context.Background(), gl.logger, fmt.Sprint(args...))
+}
+
+func (gl *grpcLogger) Fatalln(args ...any) {
+	fatal(context.Background(), gl.logger, sprintln(args...))
+}
+
+func (gl *grpcLogger) Fatalf(format string, args ...any) {
+	fatal(context.Background(), gl.logger, fmt.Sprintf(format, args...))
+}
+
+func (gl *grpcLogger) V(level int) bool {
+	return level <= gl.verbosity
+}
+
+func (gl *grpcLogger) log(level slog.Level, msg string) {
+	ctx := context.Background()
+	if !gl.logger.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	// skip runtime.Callers, log and the LoggerV2 method
+	runtime.Callers(3, pcs[:])
+
+	_ = gl.logger.Handler().Handle(ctx, slog.NewRecord(time.Now(), level, msg, pcs[0]))
+}
+
+// sprintln formats args like fmt.Sprintln without the trailing newline.
+func sprintln(args ...any) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_GRPCLogger(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+	grpcLogger := NewGRPCLogger(logger, 2)
+
+	grpcLogger.Info("connecting to ", "localhost", ":", 50051)
+	grpcLogger.Warningln("retrying", 3)
+	grpcLogger.Errorf("connection %s failed", "localhost:50051")
+
+	logtest.AssertMessages(t, records,
+		"connecting to localhost:50051",
+		"retrying 3",
+		"connection localhost:50051 failed",
+	)
+
+	levels := []slog.Level{}
+	for _, record := range records.Records() {
+		levels = append(levels, record.Level)
+	}
+	assert.Equal(t, []slog.Level{slog.LevelInfo, slog.LevelWarn, slog.LevelError}, levels)
+
+	assert.True(t, grpcLogger.V(2))
+	assert.False(t, grpcLogger.V(3))
+}
+
+func Test_GRPCLoggerFatal(t *testing.T) {
+
+	var code int
+	defer SetExitFunc(func(c int) { code = c })()
+
+	logger, records := logtest.NewLogger()
+	NewGRPCLogger(logger, 0).Fatalf("listen %d", 50051)
+
+	assert.Equal(t, 1, code)
+	require.Len(t, records.Records(), 1)
+	assert.Equal(t, LevelFatal, records.Records()[0].Level)
+}
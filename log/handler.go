@@ -1,17 +1,14 @@
 package log
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"strings"
-	"sync"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/vishenosik/web/colors"
 	"gopkg.in/yaml.v2"
 )
@@ -26,38 +23,70 @@ type customAttrs struct {
 
 type Handler struct {
 	handler slog.Handler
-	writer  io.Writer
-	rec     nextFunc
-	buf     *bytes.Buffer
-	mutex   *sync.Mutex
+	// the inner handler without attributes and groups,
+	// request-scoped attributes are added to it at the top level
+	root   slog.Handler
+	writer io.Writer
+	rec    nextFunc
+
+	// attributes and groups added with WithAttrs and WithGroup,
+	// used by the pretty output only
+	goas []groupOrAttrs
+
+	// request-scoped attributes sources
+	extractors []ContextExtractor
+	trace      TraceExtractor
 
 	// syntax highlighter
 	highlight *colors.Higlighter
 
+	// colors
+	theme   Theme
+	color   *bool
+	colored bool
+
 	// marshaller type
 	marshalType uint8
 
+	// single-line pretty output
+	compact    bool
+	valueWidth int
+
+	// output format
+	output uint8
+	level  slog.Leveler
+	levels *Levels
+
+	// source and stack trace
+	addSource  bool
+	stackLevel *slog.Level
+
+	metrics Metrics
+
 	attrs customAttrs
 }
 
+// groupOrAttrs holds either a group name or a list of attributes
+// in the order they were added to the handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
 // The signature of the function for setting parameters
 type HandlerOption func(*Handler)
 
 func defaultHandler() *Handler {
-	handlerOptions := &slog.HandlerOptions{Level: slog.LevelDebug}
-	buf := &bytes.Buffer{}
-
 	h := &Handler{
-		handler: slog.NewJSONHandler(buf, &slog.HandlerOptions{
-			Level:       handlerOptions.Level,
-			AddSource:   handlerOptions.AddSource,
-			ReplaceAttr: suppressDefaultAttrs(handlerOptions.ReplaceAttr),
-		}),
-		buf:       buf,
-		writer:    os.Stdout,
-		highlight: colors.NewHighlighter(),
-		rec:       handlerOptions.ReplaceAttr,
-		mutex:     &sync.Mutex{},
+		writer:     os.Stdout,
+		highlight:  colors.NewHighlighter(),
+		theme:      DefaultTheme(),
+		level:      slog.LevelDebug,
+		valueWidth: defaultValueWidth,
+		extractors: []ContextExtractor{
+			RequestIDExtractor,
+		},
+		trace: W3CTraceExtractor,
 	}
 
 	return h
@@ -68,33 +97,104 @@ func NewHandler(opts ...HandlerOption) *Handler {
 	for _, opt := range opts {
 		opt(h)
 	}
+	h.colored = colors.Enabled(h.writer)
+	if h.color != nil {
+		h.colored = *h.color
+	}
+	if h.metrics != nil {
+		h.writer = meteredWriter{writer: h.writer, metrics: h.metrics}
+	}
+	h.root = h.innerHandler()
+	h.handler = h.root
 	return h
 }
 
+// innerHandler builds the slog handler production outputs delegate to.
+// The pretty output renders records itself and has no inner handler.
+func (h *Handler) innerHandler() slog.Handler {
+	switch h.output {
+	case json_output:
+		return slog.NewJSONHandler(h.writer, &slog.HandlerOptions{
+			Level:       h.level,
+			AddSource:   h.addSource,
+			ReplaceAttr: replaceLevel(h.rec),
+		})
+
+	case logfmt_output:
+		return slog.NewTextHandler(h.writer, &slog.HandlerOptions{
+			Level:       h.level,
+			AddSource:   h.addSource,
+			ReplaceAttr: replaceLevel(h.rec),
+		})
+	}
+
+	return nil
+}
+
+// Flush flushes the writer if it holds records in memory, like AsyncWriter does.
+func (h *Handler) Flush() error {
+	writer := h.writer
+	if metered, ok := writer.(meteredWriter); ok {
+		writer = metered.writer
+	}
+	if flusher, ok := writer.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
 func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
+	if h.metrics == nil {
+		return h.handle(ctx, rec)
+	}
+	return h.measure(rec.Level, h.handle(ctx, rec))
+}
+
+func (h *Handler) handle(ctx context.Context, rec slog.Record) error {
+
+	// request-scoped attributes and the stack are written at the top level,
+	// outside of the groups the logger has open
+	top := append(h.contextAttrs(ctx), h.stackAttrs(rec)...)
+
+	if h.output != pretty_output {
+		return h.topHandler(top).Handle(ctx, rec)
+	}
 
 	var builder strings.Builder
 
-	builder.WriteString(fmt.Sprintf("[%s] ", rec.Time.Format(timeFormat)))
+	builder.WriteString(fmt.Sprintf("[%s] ", h.paint(h.theme.Time, rec.Time.Format(timeFormat))))
 
 	if h.attrs.component != "" {
-		builder.WriteString(fmt.Sprintf("[%s] ", color.GreenString(h.attrs.component)))
+		builder.WriteString(fmt.Sprintf("[%s] ", h.paint(h.theme.Component, h.attrs.component)))
 	}
 
-	builder.WriteString(fmt.Sprintf("%s: %s\n", level(rec), color.CyanString(rec.Message)))
+	builder.WriteString(h.levelString(rec))
 
-	attrs, err := h.computeAttrs(ctx, rec)
-	if err != nil {
+	if h.addSource {
+		if src := source(rec); src != "" {
+			builder.WriteString(fmt.Sprintf(" (%s)", h.paint(h.theme.Source, src)))
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf(": %s", h.paint(h.theme.Message, rec.Message)))
+
+	if h.compact {
+		h.writeCompact(&builder, h.computeAttrs(rec, top...))
+		_, err := io.WriteString(h.writer, builder.String())
 		return err
 	}
 
-	attrsStr, err := h.marshal(attrs)
+	builder.WriteString("\n")
+
+	attrsStr, err := h.marshal(h.computeAttrs(rec, top...))
 	if err != nil {
 		return err
 	}
 
-	attrsStr = h.highlight.HighlightNumbers(attrsStr)
-	attrsStr = h.highlight.HighlightKeyWords(attrsStr)
+	if h.colored {
+		attrsStr = h.highlight.HighlightNumbers(attrsStr)
+		attrsStr = h.highlight.HighlightKeyWords(attrsStr)
+	}
 
 	if attrsStr != "" {
 		builder.WriteString(fmt.Sprintf("%s\n", attrsStr))
@@ -109,12 +209,18 @@ func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
 }
 
 func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
-	return h.handler.Enabled(ctx, level)
+	return level >= h.minLevel()
 }
 
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
 	out := copy(h)
-	out.handler = h.handler.WithAttrs(attrs)
+	if h.handler != nil {
+		out.handler = h.handler.WithAttrs(attrs)
+	}
 
 	for _, attr := range attrs {
 		switch attr.Key {
@@ -123,12 +229,21 @@ func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		}
 	}
 
+	out.goas = append(h.goas[:len(h.goas):len(h.goas)], groupOrAttrs{attrs: attrs})
 	return out
 }
 
 func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
 	out := copy(h)
-	out.handler = h.handler.WithGroup(name)
+	if h.handler != nil {
+		out.handler = h.handler.WithGroup(name)
+	}
+
+	out.goas = append(h.goas[:len(h.goas):len(h.goas)], groupOrAttrs{group: name})
 	return out
 }
 
@@ -142,14 +257,8 @@ func WithWriter(writer io.Writer) HandlerOption {
 
 func WithLevel(level slog.Level) HandlerOption {
 	return func(h *Handler) {
-		handlerOptions := &slog.HandlerOptions{
-			Level: level,
-		}
-		h.handler = slog.NewJSONHandler(h.buf, &slog.HandlerOptions{
-			Level:       handlerOptions.Level,
-			AddSource:   handlerOptions.AddSource,
-			ReplaceAttr: suppressDefaultAttrs(handlerOptions.ReplaceAttr),
-		})
+		h.level = level
+		h.levels = nil
 	}
 }
 
@@ -171,49 +280,157 @@ func WithYamlMarshaller() HandlerOption {
 	}
 }
 
+// WithJSONOutput switches the handler to one JSON object per line.
+func WithJSONOutput() HandlerOption {
+	return func(h *Handler) {
+		h.output = json_output
+	}
+}
+
+// WithLogfmtOutput switches the handler to one logfmt record per line.
+func WithLogfmtOutput() HandlerOption {
+	return func(h *Handler) {
+		h.output = logfmt_output
+	}
+}
+
+// WithOutputFormat selects the output format by name, so it can be taken from config.
+// Supported formats are OutputPretty, OutputJSON and OutputLogfmt,
+// unknown formats fall back to OutputPretty.
+func WithOutputFormat(format string) HandlerOption {
+	return func(h *Handler) {
+		switch strings.ToLower(format) {
+		case OutputJSON:
+			h.output = json_output
+		case OutputLogfmt:
+			h.output = logfmt_output
+		default:
+			h.output = pretty_output
+		}
+	}
+}
+
+const (
+	OutputPretty = "pretty"
+	OutputJSON   = "json"
+	OutputLogfmt = "logfmt"
+)
+
 const (
 	json_marshaller uint8 = iota
 	yaml_marshaller
 )
 
+const (
+	pretty_output uint8 = iota
+	json_output
+	logfmt_output
+)
+
 type nextFunc func([]string, slog.Attr) slog.Attr
 
-type attrs = map[string]any
+// topHandler returns the inner handler with the top level attributes
+// added before the attributes and groups of the logger.
+func (h *Handler) topHandler(top []slog.Attr) slog.Handler {
+	if len(top) == 0 {
+		return h.handler
+	}
+
+	handler := h.root.WithAttrs(top)
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			handler = handler.WithGroup(goa.group)
+			continue
+		}
+		handler = handler.WithAttrs(goa.attrs)
+	}
+	return handler
+}
+
+// computeAttrs collects the handler's and the record's attributes in the order they were added,
+// nesting groups and keeping the values' types. The top attributes follow them outside of any group.
+func (h *Handler) computeAttrs(rec slog.Record, top ...slog.Attr) attrs {
 
-func suppressDefaultAttrs(next nextFunc) nextFunc {
-	return func(groups []string, a slog.Attr) slog.Attr {
-		switch a.Key {
-		case slog.TimeKey, slog.LevelKey, slog.MessageKey, AttrAppComponent:
-			return slog.Attr{}
+	out := make(attrs, 0, rec.NumAttrs())
+	groups := make([]string, 0, len(h.goas))
+
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			groups = append(groups, goa.group)
+			continue
 		}
-		if next == nil {
-			return a
+		for _, attr := range goa.attrs {
+			h.appendAttr(&out, groups, attr)
 		}
-		return next(groups, a)
 	}
+
+	rec.Attrs(func(attr slog.Attr) bool {
+		h.appendAttr(&out, groups, attr)
+		return true
+	})
+
+	for _, attr := range top {
+		h.appendAttr(&out, nil, attr)
+	}
+
+	return out
 }
 
-func (h *Handler) computeAttrs(ctx context.Context, rec slog.Record) (attrs, error) {
+// appendAttr resolves the attribute and appends it to the innermost group.
+// Groups are created only when they get a non-empty attribute.
+func (h *Handler) appendAttr(out *attrs, groups []string, attr slog.Attr) {
 
-	h.mutex.Lock()
-	defer func() {
-		h.buf.Reset()
-		h.mutex.Unlock()
-	}()
+	// the compact output expands errors below the message line
+	if value, ok := attr.Value.Any().(errorValue); ok && h.compact && attr.Value.Kind() == slog.KindLogValuer {
+		attr.Value = slog.AnyValue(errorString(value.text()))
+	}
+
+	attr.Value = attr.Value.Resolve()
 
-	if err := h.handler.Handle(ctx, rec); err != nil {
-		return nil, fmt.Errorf("error when calling inner handler's Handle: %w", err)
+	if h.rec != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = h.rec(groups, attr)
+		attr.Value = attr.Value.Resolve()
 	}
 
-	var attrs attrs
-	err := json.Unmarshal(h.buf.Bytes(), &attrs)
-	if err != nil {
-		return nil, fmt.Errorf("error when unmarshaling inner handler's Handle result: %w", err)
+	if attr.Equal(slog.Attr{}) || attr.Key == AttrAppComponent {
+		return
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		out.group(groups).append(attr.Key, value(attr.Value))
+		return
+	}
+
+	if attr.Key != "" {
+		groups = append(groups[:len(groups):len(groups)], attr.Key)
+	}
+
+	for _, groupAttr := range attr.Value.Group() {
+		h.appendAttr(out, groups, groupAttr)
 	}
+}
+
+// value converts a resolved slog.Value to a value both marshallers render as is.
+func value(val slog.Value) any {
+	switch val.Kind() {
+	case slog.KindDuration:
+		return val.Duration().String()
+
+	case slog.KindTime:
+		return val.Time().Format(time.RFC3339Nano)
 
-	return attrs, nil
+	case slog.KindAny:
+		if err, ok := val.Any().(error); ok {
+			return errorString(err.Error())
+		}
+	}
+	return val.Any()
 }
 
+// errorString is the message of an error attribute,
+// marshallers render it as a string.
+type errorString string
+
 func (h *Handler) marshal(attrs attrs) (string, error) {
 	var (
 		data []byte
@@ -223,10 +440,10 @@ func (h *Handler) marshal(attrs attrs) (string, error) {
 
 		switch h.marshalType {
 		case json_marshaller:
-			data, err = json.MarshalIndent(attrs, "", "  ")
+			data, err = attrs.marshalJSON()
 
 		case yaml_marshaller:
-			data, err = yaml.Marshal(attrs)
+			data, err = yaml.Marshal(attrs.mapSlice())
 		}
 
 		if err != nil {
@@ -239,33 +456,33 @@ func (h *Handler) marshal(attrs attrs) (string, error) {
 func copy(h *Handler) *Handler {
 	return &Handler{
 		handler:     h.handler,
+		root:        h.root,
 		writer:      h.writer,
 		highlight:   h.highlight,
-		buf:         h.buf,
+		theme:       h.theme,
+		color:       h.color,
+		colored:     h.colored,
 		rec:         h.rec,
-		mutex:       h.mutex,
+		goas:        h.goas,
+		extractors:  h.extractors,
+		trace:       h.trace,
 		marshalType: h.marshalType,
+		compact:     h.compact,
+		valueWidth:  h.valueWidth,
+		output:      h.output,
+		level:       h.level,
+		levels:      h.levels,
+		addSource:   h.addSource,
+		stackLevel:  h.stackLevel,
+		metrics:     h.metrics,
 		attrs:       h.attrs,
 	}
 }
 
-func level(rec slog.Record) string {
-	level := rec.Level.String()
-
-	switch rec.Level {
-
-	case slog.LevelDebug:
-		level = color.MagentaString(level)
-
-	case slog.LevelInfo:
-		level = color.BlueString(level)
-
-	case slog.LevelWarn:
-		level = color.YellowString(level)
-
-	case slog.LevelError:
-		level = color.RedString(level)
-
+func (h *Handler) levelString(rec slog.Record) string {
+	style, ok := h.theme.Levels[rec.Level]
+	if !ok {
+		style = levelStyle(rec.Level)
 	}
-	return level
+	return h.paint(style, LevelName(rec.Level))
 }
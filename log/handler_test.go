@@ -0,0 +1,220 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JSONOutput(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput()))
+
+	logger.With(AppComponent("api")).Info("started", slog.Int("port", 8080))
+	logger.Debug("debug")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "INFO", record[slog.LevelKey])
+	assert.Equal(t, "started", record[slog.MessageKey])
+	assert.Equal(t, "api", record[AttrAppComponent])
+	assert.EqualValues(t, 8080, record["port"])
+}
+
+func Test_LogfmtOutput(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithLogfmtOutput(), WithLevel(slog.LevelInfo)))
+
+	logger.Debug("skipped")
+	logger.Info("started", slog.Int("port", 8080))
+
+	line := strings.TrimSpace(buf.String())
+	assert.NotContains(t, line, "\n")
+	assert.Contains(t, line, "level=INFO")
+	assert.Contains(t, line, "msg=started")
+	assert.Contains(t, line, "port=8080")
+}
+
+func Test_OutputFormat(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []struct {
+		format string
+		expect uint8
+	}{
+		{OutputJSON, json_output},
+		{"JSON", json_output},
+		{OutputLogfmt, logfmt_output},
+		{OutputPretty, pretty_output},
+		{"unknown", pretty_output},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			h := NewHandler(WithOutputFormat(tt.format))
+			assert.Equal(t, tt.expect, h.output)
+		})
+	}
+}
+
+func Test_ComputeAttrs(t *testing.T) {
+
+	h := NewHandler().
+		WithAttrs([]slog.Attr{AppComponent("api"), slog.String("service", "web")}).
+		WithGroup("request").
+		WithGroup("empty").(*Handler)
+
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	rec.AddAttrs(
+		slog.Int64("id", 1<<60),
+		slog.Group("user", slog.Bool("admin", true)),
+		slog.Group("", slog.Float64("inline", 0.5)),
+		slog.Group("skipped"),
+		slog.Any("err", io.EOF),
+	)
+
+	expect := attrs{
+		{"service", "web"},
+		{"request", &attrs{
+			{"empty", &attrs{
+				{"id", int64(1 << 60)},
+				{"user", &attrs{{"admin", true}}},
+				{"inline", 0.5},
+				{"err", errorString(io.EOF.Error())},
+			}},
+		}},
+	}
+
+	assert.Equal(t, expect, h.computeAttrs(rec))
+
+	empty := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	assert.Equal(t, attrs{{"service", "web"}}, h.computeAttrs(empty))
+}
+
+func Test_PrettyOutput(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		opts   []HandlerOption
+		expect string
+	}{
+		{
+			name: "json",
+			expect: `{
+  "service": "web",
+  "request": {
+    "id": 1152921504606846977,
+    "method": "GET",
+    "user": {
+      "roles": [
+        "admin",
+        "dev"
+      ],
+      "active": true
+    },
+    "code": 200
+  }
+}
+`,
+		},
+		{
+			name: "yaml",
+			opts: []HandlerOption{WithYamlMarshaller()},
+			expect: `service: web
+request:
+  id: 1152921504606846977
+  method: GET
+  user:
+    roles:
+    - admin
+    - dev
+    active: true
+  code: 200
+
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			buf := &bytes.Buffer{}
+			opts := append([]HandlerOption{WithWriter(buf)}, tt.opts...)
+
+			logger := slog.New(NewHandler(opts...)).With(slog.String("service", "web")).WithGroup("request")
+			logger.Info("msg",
+				slog.Int64("id", 1<<60+1),
+				slog.String("method", "GET"),
+				slog.Group("user", slog.Any("roles", []string{"admin", "dev"}), slog.Bool("active", true)),
+				slog.Int("code", 200),
+			)
+
+			_, attrs, ok := strings.Cut(buf.String(), "\n")
+			require.True(t, ok)
+			assert.Equal(t, tt.expect, attrs)
+		})
+	}
+}
+
+func benchmarkLogger(opts ...HandlerOption) *slog.Logger {
+	opts = append([]HandlerOption{WithWriter(io.Discard)}, opts...)
+	return slog.New(NewHandler(opts...)).With(
+		AppComponent("bench"),
+		slog.String("service", "web"),
+	).WithGroup("request")
+}
+
+func Benchmark_Handler(b *testing.B) {
+
+	benchmarks := []struct {
+		name string
+		opts []HandlerOption
+	}{
+		{"json", nil},
+		{"yaml", []HandlerOption{WithYamlMarshaller()}},
+	}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			logger := benchmarkLogger(bb.opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				logger.Info("request accepted",
+					slog.Int("code", 200),
+					slog.String("method", "GET /api/v1/users"),
+					slog.Duration("took", time.Millisecond),
+				)
+			}
+		})
+	}
+}
+
+func Benchmark_HandlerParallel(b *testing.B) {
+
+	logger := benchmarkLogger()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info("request accepted",
+				slog.Int("code", 200),
+				slog.String("method", "GET /api/v1/users"),
+				slog.Duration("took", time.Millisecond),
+			)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package log
+
+import (
+	// builtin
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	// internal
+	"github.com/vishenosik/web/colors"
+)
+
+const (
+	LevelTrace    = slog.Level(-8)
+	LevelNotice   = slog.Level(2)
+	LevelCritical = slog.Level(12)
+	LevelFatal    = slog.Level(16)
+)
+
+type levelInfo struct {
+	name  string
+	style colors.Style
+}
+
+var (
+	levelsMutex sync.RWMutex
+	// custom levels registered by name
+	customLevels = map[slog.Level]levelInfo{
+		LevelTrace:    {"TRACE", colors.NewStyle(colors.Palette(245))},
+		LevelNotice:   {"NOTICE", colors.NewStyle(colors.Basic(colors.Cyan))},
+		LevelCritical: {"CRITICAL", colors.Style{Color: colors.Basic(colors.Red), Bold: true}},
+		LevelFatal:    {"FATAL", colors.Style{Color: colors.Basic(colors.Red), Bold: true, Underline: true}},
+	}
+
+	exitMutex sync.RWMutex
+	exitFunc  = os.Exit
+)
+
+// RegisterLevel names a custom level and sets its style in the pretty output.
+// Registered names are used by every output format and by ParseLevel.
+func RegisterLevel(level slog.Level, name string, style colors.Style) {
+	levelsMutex.Lock()
+	defer levelsMutex.Unlock()
+	customLevels[level] = levelInfo{name: strings.ToUpper(name), style: style}
+}
+
+// LevelName returns the registered name of the level, or slog's name like "INFO+1".
+func LevelName(level slog.Level) string {
+	levelsMutex.RLock()
+	defer levelsMutex.RUnlock()
+	if info, ok := customLevels[level]; ok {
+		return info.name
+	}
+	return level.String()
+}
+
+// ParseLevel parses registered level names and the names slog.Level accepts, ignoring case.
+func ParseLevel(name string) (slog.Level, error) {
+	levelsMutex.RLock()
+	for level, info := range customLevels {
+		if strings.EqualFold(info.name, name) {
+			levelsMutex.RUnlock()
+			return level, nil
+		}
+	}
+	levelsMutex.RUnlock()
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(name))
+	return level, err
+}
+
+func levelStyle(level slog.Level) colors.Style {
+	levelsMutex.RLock()
+	defer levelsMutex.RUnlock()
+	return customLevels[level].style
+}
+
+// replaceLevel writes registered level names in the JSON and logfmt outputs.
+func replaceLevel(next nextFunc) nextFunc {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.LevelKey {
+			if level, ok := a.Value.Any().(slog.Level); ok {
+				a.Value = slog.StringValue(LevelName(level))
+			}
+		}
+		if next == nil {
+			return a
+		}
+		return next(groups, a)
+	}
+}
+
+// SetExitFunc replaces the function Fatal exits the process with
+// and returns a function restoring the previous one. Tests use it to stop the exit:
+//
+//	defer log.SetExitFunc(func(code int) { exited = code })()
+func SetExitFunc(exit func(code int)) (restore func()) {
+	exitMutex.Lock()
+	defer exitMutex.Unlock()
+
+	previous := exitFunc
+	exitFunc = exit
+	return func() {
+		exitMutex.Lock()
+		defer exitMutex.Unlock()
+		exitFunc = previous
+	}
+}
+
+// Flusher is implemented by writers and handlers which hold records in memory, like AsyncWriter.
+type Flusher interface {
+	Flush() error
+}
+
+// Fatal logs the message at LevelFatal and exits with code 1.
+// The handler of the logger is flushed before the exit if it implements Flusher,
+// Handler does so for writers like AsyncWriter. Other handlers holding records
+// must be flushed by the function set with SetExitFunc.
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	fatal(context.Background(), logger, msg, args...)
+}
+
+// FatalContext logs the message at LevelFatal with the context and exits with code 1.
+func FatalContext(ctx context.Context, logger *slog.Logger, msg string, args ...any) {
+	fatal(ctx, logger, msg, args...)
+}
+
+// fatal must be called directly by the exported helpers, so that the record's source
+// points at their caller.
+func fatal(ctx context.Context, logger *slog.Logger, msg string, args ...any) {
+	if logger.Enabled(ctx, LevelFatal) {
+		var pcs [1]uintptr
+		// skip runtime.Callers, fatal and the exported helper
+		runtime.Callers(3, pcs[:])
+
+		rec := slog.NewRecord(time.Now(), LevelFatal, msg, pcs[0])
+		rec.Add(args...)
+		_ = logger.Handler().Handle(ctx, rec)
+	}
+
+	// the record must not stay in a queue of the exiting process
+	if flusher, ok := logger.Handler().(Flusher); ok {
+		_ = flusher.Flush()
+	}
+
+	exitMutex.RLock()
+	exit := exitFunc
+	exitMutex.RUnlock()
+
+	exit(1)
+}
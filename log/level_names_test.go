@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/colors"
+	"github.com/vishenosik/web/metrics"
+)
+
+func Test_LevelNames(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput(), WithLevel(LevelTrace)))
+
+	logger.Log(context.Background(), LevelTrace, "trace")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "TRACE", record[slog.LevelKey])
+
+	buf.Reset()
+	logger = slog.New(NewHandler(WithWriter(buf), WithColor(true)))
+	logger.Log(context.Background(), LevelNotice, "notice")
+	assert.Contains(t, buf.String(), "\x1b[36mNOTICE\x1b[0m: ")
+
+	assert.Equal(t, "INFO+1", LevelName(slog.LevelInfo+1))
+}
+
+func Test_RegisterLevel(t *testing.T) {
+
+	level := slog.Level(6)
+	RegisterLevel(level, "alert", colors.NewStyle(colors.Basic(colors.Yellow)))
+
+	assert.Equal(t, "ALERT", LevelName(level))
+
+	parsed, err := ParseLevel("Alert")
+	require.NoError(t, err)
+	assert.Equal(t, level, parsed)
+
+	parsed, err = ParseLevel("warn+1")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn+1, parsed)
+
+	_, err = ParseLevel("loud")
+	assert.Error(t, err)
+}
+
+func Test_Fatal(t *testing.T) {
+
+	code := -1
+	defer SetExitFunc(func(c int) { code = c })()
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithSource()))
+
+	Fatal(logger, "fatal", slog.Int("attempt", 3))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, buf.String(), "FATAL (log/level_names_test.go:")
+	assert.Contains(t, buf.String(), `"attempt": 3`)
+
+	code = -1
+	buf.Reset()
+	NewStdLogger(logger).Fatalf("migration %d failed", 2)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, buf.String(), "FATAL (log/level_names_test.go:")
+	assert.Contains(t, buf.String(), "migration 2 failed")
+}
+
+func Test_FatalFlush(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	w := NewAsyncWriter(buf)
+	defer w.Close()
+
+	var written string
+	defer SetExitFunc(func(int) { written = buf.String() })()
+
+	logger := slog.New(NewHandler(WithWriter(w), WithJSONOutput(), WithMetrics(NewRegistryMetrics(metrics.NewRegistry())))).
+		With(AppComponent("db"))
+
+	Fatal(logger, "fatal")
+	assert.Contains(t, written, `"msg":"fatal"`)
+
+	NewStdLogger(logger).Fatalf("migration %d failed", 2)
+	assert.Contains(t, written, `"msg":"migration 2 failed"`)
+
+	NewGRPCLogger(logger, 0).Fatal("listen failed")
+	assert.Contains(t, written, `"msg":"listen failed"`)
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+// Levels holds the minimum level of handlers and can be changed while the process runs.
+// Components, set with the AttrAppComponent attribute, can override the level.
+// Levels implements slog.Leveler.
+type Levels struct {
+	level *slog.LevelVar
+
+	mutex      sync.RWMutex
+	components map[string]slog.Level
+}
+
+// NewLevels creates Levels backed by level. A nil level starts at slog.LevelDebug,
+// the default level of Handler.
+func NewLevels(level *slog.LevelVar) *Levels {
+	if level == nil {
+		level = new(slog.LevelVar)
+		level.Set(slog.LevelDebug)
+	}
+	return &Levels{
+		level:      level,
+		components: make(map[string]slog.Level),
+	}
+}
+
+// Level returns the base level.
+func (l *Levels) Level() slog.Level {
+	return l.level.Level()
+}
+
+// SetLevel changes the base level.
+func (l *Levels) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// ComponentLevel returns the level override of the component,
+// or the base level if the component has none.
+func (l *Levels) ComponentLevel(component string) slog.Level {
+	if component != "" {
+		l.mutex.RLock()
+		level, ok := l.components[component]
+		l.mutex.RUnlock()
+		if ok {
+			return level
+		}
+	}
+	return l.Level()
+}
+
+// SetComponentLevel overrides the level of the component.
+func (l *Levels) SetComponentLevel(component string, level slog.Level) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.components[component] = level
+}
+
+// ResetComponentLevel removes the level override of the component.
+func (l *Levels) ResetComponentLevel(component string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.components, component)
+}
+
+// Components returns a copy of the component overrides.
+func (l *Levels) Components() map[string]slog.Level {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return maps.Clone(l.components)
+}
+
+// WithLevelVar makes the handler follow level, which can be changed at runtime.
+func WithLevelVar(level *slog.LevelVar) HandlerOption {
+	return func(h *Handler) {
+		if level != nil {
+			h.level = level
+			h.levels = nil
+		}
+	}
+}
+
+// WithLevels makes the handler follow levels, including component overrides.
+func WithLevels(levels *Levels) HandlerOption {
+	return func(h *Handler) {
+		if levels != nil {
+			h.level = levels
+			h.levels = levels
+		}
+	}
+}
+
+// minLevel returns the level of the handler's component.
+func (h *Handler) minLevel() slog.Level {
+	if h.levels != nil {
+		return h.levels.ComponentLevel(h.attrs.component)
+	}
+	return h.level.Level()
+}
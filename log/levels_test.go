@@ -0,0 +1,46 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Levels(t *testing.T) {
+
+	levels := NewLevels(nil)
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithLevels(levels)))
+	storage := logger.With(AppComponent("storage"))
+
+	ctx := context.Background()
+	assert.True(t, logger.Enabled(ctx, slog.LevelDebug))
+
+	levels.SetLevel(slog.LevelWarn)
+	assert.False(t, logger.Enabled(ctx, slog.LevelInfo))
+	assert.False(t, storage.Enabled(ctx, slog.LevelInfo))
+
+	levels.SetComponentLevel("storage", slog.LevelDebug)
+	assert.False(t, logger.Enabled(ctx, slog.LevelInfo))
+	assert.True(t, storage.Enabled(ctx, slog.LevelDebug))
+	assert.Equal(t, map[string]slog.Level{"storage": slog.LevelDebug}, levels.Components())
+
+	levels.ResetComponentLevel("storage")
+	assert.False(t, storage.Enabled(ctx, slog.LevelInfo))
+	assert.Empty(t, levels.Components())
+}
+
+func Test_LevelVar(t *testing.T) {
+
+	level := new(slog.LevelVar)
+	logger := slog.New(NewHandler(WithLevelVar(level), WithJSONOutput()))
+
+	ctx := context.Background()
+	assert.False(t, logger.Enabled(ctx, slog.LevelDebug))
+
+	level.Set(slog.LevelDebug)
+	assert.True(t, logger.Enabled(ctx, slog.LevelDebug))
+}
@@ -0,0 +1,132 @@
+package logtest
+
+import (
+	// builtin
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TestingT is the subset of testing.TB the assertions use.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Match reports whether the record has the level, the message and every attribute.
+// Expected groups match groups having at least their attributes.
+func (r Record) Match(level slog.Level, msg string, attrs ...slog.Attr) bool {
+	return r.Level == level && r.Message == msg && contains(r.Attrs, attrs)
+}
+
+// AssertLogged checks that a record matching Record.Match was captured.
+func AssertLogged(t TestingT, h *Handler, level slog.Level, msg string, attrs ...slog.Attr) bool {
+	t.Helper()
+
+	for _, record := range h.Records() {
+		if record.Match(level, msg, attrs...) {
+			return true
+		}
+	}
+
+	t.Errorf("no record %s %q %s was logged, captured records:\n%s", level, msg, formatAttrs(attrs), formatRecords(h.Records()))
+	return false
+}
+
+// AssertNotLogged checks that no record with the level and the message was captured.
+func AssertNotLogged(t TestingT, h *Handler, level slog.Level, msg string) bool {
+	t.Helper()
+
+	for _, record := range h.Records() {
+		if record.Level == level && record.Message == msg {
+			t.Errorf("unexpected record %s", formatRecord(record))
+			return false
+		}
+	}
+	return true
+}
+
+// AssertEventuallyLogged waits up to the timeout for a record matching Record.Match,
+// for records logged by other goroutines.
+func AssertEventuallyLogged(t TestingT, h *Handler, timeout time.Duration, level slog.Level, msg string, attrs ...slog.Attr) bool {
+	t.Helper()
+
+	_, ok := h.WaitFor(timeout, func(record Record) bool {
+		return record.Match(level, msg, attrs...)
+	})
+	if !ok {
+		t.Errorf("no record %s %q %s was logged in %s, captured records:\n%s", level, msg, formatAttrs(attrs), timeout, formatRecords(h.Records()))
+	}
+	return ok
+}
+
+// AssertMessages checks the messages of all captured records in order.
+func AssertMessages(t TestingT, h *Handler, messages ...string) bool {
+	t.Helper()
+
+	records := h.Records()
+	actual := make([]string, len(records))
+	for i := range records {
+		actual[i] = records[i].Message
+	}
+
+	if !slices.Equal(actual, messages) {
+		t.Errorf("expected messages %q, got %q", messages, actual)
+		return false
+	}
+	return true
+}
+
+func contains(actual, expected []slog.Attr) bool {
+	for _, attr := range expected {
+		attr.Value = attr.Value.Resolve()
+
+		found, ok := find(actual, attr.Key)
+		if !ok {
+			return false
+		}
+
+		if attr.Value.Kind() == slog.KindGroup {
+			if found.Value.Kind() != slog.KindGroup || !contains(found.Value.Group(), attr.Value.Group()) {
+				return false
+			}
+			continue
+		}
+
+		if !equal(found.Value, attr.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares values like slog.Value.Equal, without panicking on uncomparable values.
+func equal(a, b slog.Value) bool {
+	if a.Kind() == slog.KindAny || b.Kind() == slog.KindAny {
+		return reflect.DeepEqual(a.Any(), b.Any())
+	}
+	return a.Equal(b)
+}
+
+func formatRecords(records []Record) string {
+	lines := make([]string, len(records))
+	for i := range records {
+		lines[i] = "\t" + formatRecord(records[i])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatRecord(record Record) string {
+	return fmt.Sprintf("%s %q %s", record.Level, record.Message, formatAttrs(record.Attrs))
+}
+
+func formatAttrs(attrs []slog.Attr) string {
+	parts := make([]string, len(attrs))
+	for i := range attrs {
+		parts[i] = attrs[i].String()
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
@@ -0,0 +1,223 @@
+// Package logtest provides a slog.Handler recording records in memory,
+// so that tests can assert on what was logged instead of parsing the output.
+package logtest
+
+import (
+	// builtin
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Record is a captured log record. Attributes of the handler and the record
+// are nested into groups the same way a slog handler would output them.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr
+}
+
+// Handler records every enabled record. Handlers derived with WithAttrs
+// and WithGroup share the records of the handler they were derived from.
+type Handler struct {
+	level slog.Leveler
+	goas  []groupOrAttrs
+	state *state
+}
+
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+type state struct {
+	mutex   sync.Mutex
+	records []Record
+	// closed and replaced on every new record and reset
+	notify chan struct{}
+	// bumped by Reset, so that waiters check the records from the start again
+	generation uint64
+}
+
+// NewHandler returns a Handler recording records at or above the level.
+func NewHandler(level slog.Leveler) *Handler {
+	return &Handler{
+		level: level,
+		state: &state{notify: make(chan struct{})},
+	}
+}
+
+// NewLogger returns a logger recording records of every level and its Handler.
+func NewLogger() (*slog.Logger, *Handler) {
+	h := NewHandler(slog.Level(-128))
+	return slog.New(h), h
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
+
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	rec.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		goa := h.goas[i]
+		if goa.group == "" {
+			attrs = append(slices.Clone(goa.attrs), attrs...)
+			continue
+		}
+		if len(attrs) > 0 {
+			attrs = []slog.Attr{slog.Group(goa.group, anys(attrs)...)}
+		}
+	}
+
+	record := Record{
+		Time:    rec.Time,
+		Level:   rec.Level,
+		Message: rec.Message,
+		Attrs:   resolve(attrs),
+	}
+
+	h.state.mutex.Lock()
+	h.state.records = append(h.state.records, record)
+	close(h.state.notify)
+	h.state.notify = make(chan struct{})
+	h.state.mutex.Unlock()
+
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	out := *h
+	out.goas = append(slices.Clip(h.goas), groupOrAttrs{attrs: attrs})
+	return &out
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	out := *h
+	out.goas = append(slices.Clip(h.goas), groupOrAttrs{group: name})
+	return &out
+}
+
+// Records returns the records captured so far in the order they were logged.
+func (h *Handler) Records() []Record {
+	h.state.mutex.Lock()
+	defer h.state.mutex.Unlock()
+	return slices.Clone(h.state.records)
+}
+
+// Reset forgets the captured records.
+func (h *Handler) Reset() {
+	h.state.mutex.Lock()
+	defer h.state.mutex.Unlock()
+	h.state.records = nil
+	h.state.generation++
+	close(h.state.notify)
+	h.state.notify = make(chan struct{})
+}
+
+// WaitFor blocks until a record matching the function is captured, including records
+// captured before the call, or until the timeout expires.
+func (h *Handler) WaitFor(timeout time.Duration, match func(Record) bool) (Record, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	checked, generation := 0, uint64(0)
+	for {
+		h.state.mutex.Lock()
+		if h.state.generation != generation {
+			checked, generation = 0, h.state.generation
+		}
+		records := h.state.records[min(checked, len(h.state.records)):]
+		checked = len(h.state.records)
+		notify := h.state.notify
+		records = slices.Clone(records)
+		h.state.mutex.Unlock()
+
+		for _, record := range records {
+			if match(record) {
+				return record, true
+			}
+		}
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return Record{}, false
+		}
+	}
+}
+
+// Attr returns the attribute at the path of keys, one key per nested group.
+func (r Record) Attr(path ...string) (slog.Value, bool) {
+	attrs := r.Attrs
+	for i, key := range path {
+		attr, ok := find(attrs, key)
+		if !ok {
+			return slog.Value{}, false
+		}
+		if i == len(path)-1 {
+			return attr.Value, true
+		}
+		if attr.Value.Kind() != slog.KindGroup {
+			return slog.Value{}, false
+		}
+		attrs = attr.Value.Group()
+	}
+	return slog.Value{}, false
+}
+
+func find(attrs []slog.Attr, key string) (slog.Attr, bool) {
+	// the last attribute wins, as in the output of slog handlers
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Key == key {
+			return attrs[i], true
+		}
+	}
+	return slog.Attr{}, false
+}
+
+// resolve resolves LogValuers, inlines groups with empty keys and drops empty groups.
+func resolve(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Value.Kind() != slog.KindGroup {
+			out = append(out, attr)
+			continue
+		}
+
+		group := resolve(attr.Value.Group())
+		if len(group) == 0 {
+			continue
+		}
+		if attr.Key == "" {
+			out = append(out, group...)
+			continue
+		}
+		out = append(out, slog.Attr{Key: attr.Key, Value: slog.GroupValue(group...)})
+	}
+	return out
+}
+
+func anys(attrs []slog.Attr) []any {
+	out := make([]any, len(attrs))
+	for i := range attrs {
+		out[i] = attrs[i]
+	}
+	return out
+}
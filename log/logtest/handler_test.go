@@ -0,0 +1,128 @@
+package logtest
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_Handler(t *testing.T) {
+
+	logger, h := NewLogger()
+
+	logger.With(slog.String("service", "web")).
+		WithGroup("request").
+		WithGroup("empty").
+		Info("first", slog.Int("code", 200), slog.Group("user", slog.String("id", "1")))
+	logger.WithGroup("unused").Warn("second", slog.Any("roles", []string{"admin"}))
+
+	records := h.Records()
+	require.Len(t, records, 2)
+
+	assert.Equal(t, slog.LevelInfo, records[0].Level)
+	assert.Equal(t, []slog.Attr{
+		slog.String("service", "web"),
+		slog.Group("request", slog.Group("empty", slog.Int("code", 200), slog.Group("user", slog.String("id", "1")))),
+	}, records[0].Attrs)
+
+	code, ok := records[0].Attr("request", "empty", "code")
+	require.True(t, ok)
+	assert.EqualValues(t, 200, code.Int64())
+
+	_, ok = records[0].Attr("request", "code")
+	assert.False(t, ok)
+
+	assert.True(t, AssertLogged(t, h, slog.LevelInfo, "first",
+		slog.String("service", "web"),
+		slog.Group("request", slog.Group("empty", slog.Group("user", slog.String("id", "1")))),
+	))
+	assert.True(t, AssertLogged(t, h, slog.LevelWarn, "second", slog.Group("unused", slog.Any("roles", []string{"admin"}))))
+	assert.True(t, AssertNotLogged(t, h, slog.LevelError, "first"))
+	assert.True(t, AssertMessages(t, h, "first", "second"))
+
+	h.Reset()
+	assert.True(t, AssertMessages(t, h))
+}
+
+func Test_AssertFailures(t *testing.T) {
+
+	logger, h := NewLogger()
+	logger.Info("msg", slog.Int("code", 200))
+
+	ft := &fakeT{}
+	assert.False(t, AssertLogged(ft, h, slog.LevelInfo, "msg", slog.Int("code", 500)))
+	assert.False(t, AssertLogged(ft, h, slog.LevelError, "msg"))
+	assert.False(t, AssertNotLogged(ft, h, slog.LevelInfo, "msg"))
+	assert.False(t, AssertMessages(ft, h, "other"))
+	assert.False(t, AssertEventuallyLogged(ft, h, 10*time.Millisecond, slog.LevelInfo, "never"))
+	assert.Len(t, ft.errors, 5)
+}
+
+func Test_WaitFor(t *testing.T) {
+
+	logger, h := NewLogger()
+	logger.Info("before")
+
+	go func() {
+		for i := range 3 {
+			time.Sleep(5 * time.Millisecond)
+			logger.Info("async", slog.Int("i", i))
+		}
+	}()
+
+	record, ok := h.WaitFor(time.Second, func(r Record) bool {
+		i, ok := r.Attr("i")
+		return ok && i.Int64() == 2
+	})
+	require.True(t, ok)
+	assert.Equal(t, "async", record.Message)
+
+	_, ok = h.WaitFor(time.Second, func(r Record) bool { return r.Message == "before" })
+	assert.True(t, ok)
+
+	assert.True(t, AssertEventuallyLogged(t, h, time.Second, slog.LevelInfo, "async", slog.Int("i", 1)))
+}
+
+func Test_WaitForReset(t *testing.T) {
+
+	logger, h := NewLogger()
+	for range 3 {
+		logger.Info("old")
+	}
+
+	done := make(chan bool)
+	go func() {
+		_, ok := h.WaitFor(time.Second, func(r Record) bool { return r.Message == "new" })
+		done <- ok
+	}()
+
+	// the records checked before the reset must not hide the new ones
+	time.Sleep(10 * time.Millisecond)
+	h.Reset()
+	logger.Info("new")
+
+	assert.True(t, <-done)
+}
+
+func Test_Level(t *testing.T) {
+
+	h := NewHandler(slog.LevelWarn)
+	slog.New(h).Info("skipped")
+	slog.New(h).Error("recorded")
+
+	assert.True(t, AssertMessages(t, h, "recorded"))
+}
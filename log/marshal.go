@@ -0,0 +1,134 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	jsonIndent = "  "
+)
+
+// attrs is an ordered list of attributes of the pretty output.
+// Values are either resolved attribute values or *attrs of nested groups.
+type attrs []attr
+
+type attr struct {
+	key   string
+	value any
+}
+
+func (a *attrs) append(key string, value any) {
+	*a = append(*a, attr{key: key, value: value})
+}
+
+// group returns the innermost group, creating missing ones on the way.
+// Attributes of a group logged more than once are merged into one section.
+func (a *attrs) group(groups []string) *attrs {
+	out := a
+	for _, name := range groups {
+		out = out.subgroup(name)
+	}
+	return out
+}
+
+func (a *attrs) subgroup(name string) *attrs {
+	for _, item := range *a {
+		if group, ok := item.value.(*attrs); ok && item.key == name {
+			return group
+		}
+	}
+	group := &attrs{}
+	a.append(name, group)
+	return group
+}
+
+// mapSlice converts attributes to the yaml ordered map.
+func (a attrs) mapSlice() yaml.MapSlice {
+	out := make(yaml.MapSlice, 0, len(a))
+	for _, item := range a {
+		value := item.value
+		if group, ok := value.(*attrs); ok {
+			value = group.mapSlice()
+		}
+		out = append(out, yaml.MapItem{Key: item.key, Value: value})
+	}
+	return out
+}
+
+// marshalJSON renders attributes as indented JSON keeping their order.
+func (a attrs) marshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := a.writeJSON(buf, ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (a attrs) writeJSON(buf *bytes.Buffer, indent string) error {
+	inner := indent + jsonIndent
+
+	buf.WriteString("{\n")
+	for i, item := range a {
+
+		key, err := json.Marshal(item.key)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteString(inner)
+		buf.Write(key)
+		buf.WriteString(": ")
+
+		if group, ok := item.value.(*attrs); ok {
+			if err := group.writeJSON(buf, inner); err != nil {
+				return err
+			}
+		} else if err := writeJSONValue(buf, item.value, inner); err != nil {
+			return err
+		}
+
+		if i < len(a)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(indent + "}")
+
+	return nil
+}
+
+// writeJSONValue writes scalars directly and falls back to encoding/json for the rest.
+func writeJSONValue(buf *bytes.Buffer, value any, indent string) error {
+	switch v := value.(type) {
+	case int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), v, 10))
+		return nil
+
+	case uint64:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), v, 10))
+		return nil
+
+	case bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), v))
+		return nil
+
+	case string:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		return nil
+	}
+
+	data, err := json.MarshalIndent(value, indent, jsonIndent)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
@@ -0,0 +1,132 @@
+package log
+
+import (
+	// builtin
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	// internal
+	"github.com/vishenosik/web/metrics"
+)
+
+// Metrics receives measurements of Handler and AsyncWriter.
+type Metrics interface {
+	// Record is called for every record handled.
+	Record(level slog.Level)
+	// Dropped is called for every record discarded by a full AsyncWriter.
+	Dropped()
+	// MarshalError is called when a record fails to be formatted.
+	MarshalError()
+	// WriteError is called when the writer fails.
+	WriteError()
+	// WriteLatency is called with the duration of every write.
+	WriteLatency(took time.Duration)
+}
+
+// writeLatencyBuckets are upper bounds in seconds, from 10µs to 1s.
+var writeLatencyBuckets = []float64{.00001, .00005, .0001, .0005, .001, .005, .01, .05, .1, .5, 1}
+
+// RegistryMetrics implements Metrics with the metrics package.
+// Its fields can be read directly, for example
+//
+//	m.Records.Value("ERROR")
+type RegistryMetrics struct {
+	Records        *metrics.Counter
+	DroppedRecords *metrics.Counter
+	MarshalErrors  *metrics.Counter
+	WriteErrors    *metrics.Counter
+	WriteDuration  *metrics.Histogram
+}
+
+// NewRegistryMetrics registers log metrics in the registry.
+// Handlers sharing the registry share the metrics, registry.Handler serves them.
+func NewRegistryMetrics(registry *metrics.Registry) *RegistryMetrics {
+	return &RegistryMetrics{
+		Records:        registry.Counter("log_records_total", "Log records handled by level.", "level"),
+		DroppedRecords: registry.Counter("log_dropped_records_total", "Log records dropped because the queue was full."),
+		MarshalErrors:  registry.Counter("log_marshal_errors_total", "Log records which failed to be formatted."),
+		WriteErrors:    registry.Counter("log_write_errors_total", "Failed writes of log records."),
+		WriteDuration:  registry.Histogram("log_write_duration_seconds", "Duration of writes of log records.", writeLatencyBuckets),
+	}
+}
+
+func (m *RegistryMetrics) Record(level slog.Level) {
+	m.Records.Inc(LevelName(level))
+}
+
+func (m *RegistryMetrics) Dropped() {
+	m.DroppedRecords.Inc()
+}
+
+func (m *RegistryMetrics) MarshalError() {
+	m.MarshalErrors.Inc()
+}
+
+func (m *RegistryMetrics) WriteError() {
+	m.WriteErrors.Inc()
+}
+
+func (m *RegistryMetrics) WriteLatency(took time.Duration) {
+	m.WriteDuration.Observe(took.Seconds())
+}
+
+// WithMetrics reports handled records, errors and write latency of the handler.
+func WithMetrics(m Metrics) HandlerOption {
+	return func(h *Handler) {
+		h.metrics = m
+	}
+}
+
+// WithAsyncMetrics reports records the AsyncWriter drops.
+func WithAsyncMetrics(m Metrics) AsyncOption {
+	return func(w *AsyncWriter) {
+		w.metrics = m
+	}
+}
+
+// meteredWriter measures writes of the handler.
+type meteredWriter struct {
+	writer  io.Writer
+	metrics Metrics
+}
+
+// writeError marks errors of the writer, so that Handle tells them from marshal errors.
+type writeError struct {
+	err error
+}
+
+func (e *writeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *writeError) Unwrap() error {
+	return e.err
+}
+
+func (w meteredWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := w.writer.Write(p)
+	w.metrics.WriteLatency(time.Since(start))
+
+	if err != nil {
+		w.metrics.WriteError()
+		return n, &writeError{err: err}
+	}
+	return n, nil
+}
+
+// measure counts the record and the error of handling it.
+func (h *Handler) measure(level slog.Level, err error) error {
+	h.metrics.Record(level)
+
+	var werr *writeError
+	if errors.As(err, &werr) {
+		return werr.err
+	}
+	if err != nil {
+		h.metrics.MarshalError()
+	}
+	return err
+}
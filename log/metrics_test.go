@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/metrics"
+)
+
+func Test_HandlerMetrics(t *testing.T) {
+
+	registry := metrics.NewRegistry()
+	m := NewRegistryMetrics(registry)
+
+	logger := slog.New(NewHandler(WithWriter(&bytes.Buffer{}), WithMetrics(m)))
+	logger.Info("info")
+	logger.Error("error")
+	logger.Error("error")
+	logger.Info("unsupported", slog.Any("channel", make(chan int)))
+
+	assert.Equal(t, float64(2), m.Records.Value("INFO"))
+	assert.Equal(t, float64(2), m.Records.Value("ERROR"))
+	assert.Equal(t, float64(1), m.MarshalErrors.Value())
+	assert.Equal(t, uint64(3), m.WriteDuration.Count())
+
+	errWrite := errors.New("disk full")
+	err := NewHandler(WithWriter(failWriter{errWrite}), WithJSONOutput(), WithMetrics(m)).
+		Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelWarn, "warn", 0))
+
+	assert.Same(t, errWrite, err)
+	assert.Equal(t, float64(1), m.WriteErrors.Value())
+	assert.Equal(t, float64(1), m.MarshalErrors.Value())
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, registry.WriteText(buf))
+	assert.Contains(t, buf.String(), `log_records_total{level="WARN"} 1`)
+}
+
+func Test_AsyncWriterMetrics(t *testing.T) {
+
+	m := NewRegistryMetrics(metrics.NewRegistry())
+
+	gw := newGateWriter()
+	w := NewAsyncWriter(gw, WithQueueSize(1), WithBatchSize(1), WithOverflowPolicy(DropNewest), WithAsyncMetrics(m))
+
+	fmt.Fprintln(w, 0)
+	<-gw.started
+	for i := 1; i < 4; i++ {
+		fmt.Fprintln(w, i)
+	}
+
+	close(gw.gate)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, float64(2), m.DroppedRecords.Value())
+}
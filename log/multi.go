@@ -0,0 +1,97 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// MultiHandler passes every record to several handlers,
+// each of them decides on its own whether the record is enabled.
+//
+// Example:
+//
+//	logger := slog.New(NewMultiHandler(
+//	    NewHandler(WithWriter(os.Stderr)),
+//	    NewHandler(WithWriter(file), WithJSONOutput(), WithLevel(slog.LevelInfo)),
+//	    NewLevelHandler(slog.LevelError, NewHandler(WithWriter(alerts), WithJSONOutput())),
+//	))
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{
+		handlers: handlers,
+	}
+}
+
+// Enabled reports whether any of the handlers is enabled for the level.
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes the record to every enabled handler and joins their errors.
+func (h *MultiHandler) Handle(ctx context.Context, rec slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, rec.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, handler := range h.handlers {
+		handlers = append(handlers, handler.WithAttrs(attrs))
+	}
+	return NewMultiHandler(handlers...)
+}
+
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, handler := range h.handlers {
+		handlers = append(handlers, handler.WithGroup(name))
+	}
+	return NewMultiHandler(handlers...)
+}
+
+// LevelHandler passes records at or above the level to the next handler,
+// so that any handler can be given its own level inside MultiHandler.
+type LevelHandler struct {
+	level slog.Leveler
+	next  slog.Handler
+}
+
+func NewLevelHandler(level slog.Leveler, next slog.Handler) *LevelHandler {
+	return &LevelHandler{
+		level: level,
+		next:  next,
+	}
+}
+
+func (h *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.next.Enabled(ctx, level)
+}
+
+func (h *LevelHandler) Handle(ctx context.Context, rec slog.Record) error {
+	return h.next.Handle(ctx, rec)
+}
+
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewLevelHandler(h.level, h.next.WithAttrs(attrs))
+}
+
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return NewLevelHandler(h.level, h.next.WithGroup(name))
+}
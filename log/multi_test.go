@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failWriter struct {
+	err error
+}
+
+func (w failWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func Test_MultiHandler(t *testing.T) {
+
+	pretty, file, alerts := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+
+	logger := slog.New(NewMultiHandler(
+		NewHandler(WithWriter(pretty)),
+		NewHandler(WithWriter(file), WithJSONOutput(), WithLevel(slog.LevelInfo)),
+		NewLevelHandler(slog.LevelError, NewHandler(WithWriter(alerts), WithJSONOutput())),
+	)).With(AppComponent("api")).WithGroup("request")
+
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error", slog.Int("code", 500))
+
+	assert.Equal(t, 3, strings.Count(pretty.String(), "[api]"))
+	assert.Equal(t, 2, strings.Count(file.String(), "\n"))
+	require.Equal(t, 1, strings.Count(alerts.String(), "\n"))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(alerts.Bytes(), &record))
+	assert.Equal(t, "api", record[AttrAppComponent])
+	assert.Equal(t, map[string]any{"code": float64(500)}, record["request"])
+}
+
+func Test_MultiHandlerErrors(t *testing.T) {
+
+	err1, err2 := errors.New("first"), errors.New("second")
+	ok := &bytes.Buffer{}
+
+	handler := NewMultiHandler(
+		NewHandler(WithWriter(failWriter{err1})),
+		NewHandler(WithWriter(ok)),
+		NewHandler(WithWriter(failWriter{err2}), WithJSONOutput()),
+	)
+
+	err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0))
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+	assert.Contains(t, ok.String(), "msg")
+
+	assert.False(t, NewMultiHandler(NewLevelHandler(slog.LevelWarn, NewHandler())).Enabled(context.Background(), slog.LevelInfo))
+}
@@ -0,0 +1,377 @@
+package log
+
+import (
+	// builtin
+	"encoding"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// RedactTag is the struct tag marking fields which values must be masked:
+	//
+	//	Password string `log:"redact"`
+	RedactTag   = "log"
+	redactValue = "redact"
+
+	defaultRedactMask = "[REDACTED]"
+
+	// maxRedactDepth limits the nesting of expanded values, deeper values are masked
+	maxRedactDepth = 32
+)
+
+var (
+	// CardNumberRegex matches 13 to 19 digit card numbers, optionally split by spaces or dashes.
+	CardNumberRegex = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	// EmailRegex matches email addresses.
+	EmailRegex = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+
+	defaultRedactKeys = []string{"password", "token", "authorization"}
+
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Redactor masks sensitive attribute values by key name, by regular expression
+// and by the RedactTag struct tag of logged structs.
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+	mask     string
+
+	// cache of struct types which have fields to redact
+	types sync.Map
+}
+
+// The signature of the function for setting Redactor parameters
+type RedactOption func(*Redactor)
+
+// NewRedactor creates a Redactor masking the "password", "token" and "authorization" keys.
+func NewRedactor(opts ...RedactOption) *Redactor {
+	r := &Redactor{
+		keys: defaultRedactKeys,
+		mask: defaultRedactMask,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RedactKeys adds key names which values are masked.
+// Keys match case-insensitively, either whole or as the last part
+// of a key separated by "_", "-" or ".", so "token" matches "access_token".
+func RedactKeys(keys ...string) RedactOption {
+	return func(r *Redactor) {
+		r.keys = slices.Clone(r.keys)
+		for _, key := range keys {
+			r.keys = append(r.keys, strings.ToLower(key))
+		}
+	}
+}
+
+// RedactPatterns adds regular expressions which matches are masked in string values.
+func RedactPatterns(patterns ...*regexp.Regexp) RedactOption {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, patterns...)
+	}
+}
+
+// RedactMask sets the string masked values are replaced with.
+func RedactMask(mask string) RedactOption {
+	return func(r *Redactor) {
+		r.mask = mask
+	}
+}
+
+// WithRedaction masks sensitive attribute values in every output format.
+func WithRedaction(opts ...RedactOption) HandlerOption {
+	return func(h *Handler) {
+		h.rec = NewRedactor(opts...).replaceAttr(h.rec)
+	}
+}
+
+// ReplaceAttr masks the attribute value if needed. It can be used
+// as slog.HandlerOptions.ReplaceAttr of any slog handler.
+func (r *Redactor) ReplaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if r.matchKey(attr.Key) {
+		return slog.String(attr.Key, r.mask)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(r.redactString(attr.Value.String()))
+
+	case slog.KindAny:
+		attr.Value = r.redactAny(attr.Value.Any())
+	}
+
+	return attr
+}
+
+func (r *Redactor) replaceAttr(next nextFunc) nextFunc {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		a = r.ReplaceAttr(groups, a)
+		if next == nil {
+			return a
+		}
+		return next(groups, a)
+	}
+}
+
+func (r *Redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, redactKey := range r.keys {
+		if key == redactKey {
+			return true
+		}
+		for _, sep := range []string{"_", "-", "."} {
+			if strings.HasSuffix(key, sep+redactKey) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactString(value string) string {
+	for _, pattern := range r.patterns {
+		value = pattern.ReplaceAllString(value, r.mask)
+	}
+	return value
+}
+
+// redactAny turns structs with fields to redact and string-keyed maps into groups,
+// so that their fields go through ReplaceAttr one by one. Slices and arrays are expanded
+// element by element.
+func (r *Redactor) redactAny(value any) slog.Value {
+	return r.redactValue(value, make(map[uintptr]struct{}), 0)
+}
+
+// redactValue expands the value, visited holds pointers and maps on the path to it,
+// so that values referencing themselves are masked instead of expanded forever.
+func (r *Redactor) redactValue(value any, visited map[uintptr]struct{}, depth int) slog.Value {
+
+	if _, ok := value.(*slog.Source); ok {
+		return slog.AnyValue(value)
+	}
+
+	if err, ok := value.(error); ok {
+		if message := err.Error(); len(r.patterns) > 0 && r.redactString(message) != message {
+			return slog.StringValue(r.redactString(message))
+		}
+		return slog.AnyValue(value)
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return slog.AnyValue(value)
+		}
+		if rv.Kind() == reflect.Pointer {
+			if !enter(visited, rv.Pointer()) {
+				return slog.StringValue(r.mask)
+			}
+			defer delete(visited, rv.Pointer())
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if r.needsRedaction(rv.Type()) {
+			if depth >= maxRedactDepth {
+				return slog.StringValue(r.mask)
+			}
+			return slog.GroupValue(r.structAttrs(rv, visited, depth)...)
+		}
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String && !isMarshaler(rv.Type()) {
+			if rv.IsNil() {
+				return slog.AnyValue(value)
+			}
+			if depth >= maxRedactDepth || !enter(visited, rv.Pointer()) {
+				return slog.StringValue(r.mask)
+			}
+			defer delete(visited, rv.Pointer())
+			return slog.GroupValue(r.mapAttrs(rv, visited, depth)...)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if r.needsRedaction(rv.Type()) {
+			if rv.Kind() == reflect.Slice && rv.IsNil() {
+				return slog.AnyValue(value)
+			}
+			if depth >= maxRedactDepth {
+				return slog.StringValue(r.mask)
+			}
+			if rv.Kind() == reflect.Slice && rv.Len() > 0 {
+				if !enter(visited, rv.Pointer()) {
+					return slog.StringValue(r.mask)
+				}
+				defer delete(visited, rv.Pointer())
+			}
+			return slog.AnyValue(r.sliceValues(rv, visited, depth))
+		}
+	}
+
+	return slog.AnyValue(value)
+}
+
+// enter marks the pointer visited, it returns false if it already was.
+func enter(visited map[uintptr]struct{}, pointer uintptr) bool {
+	if _, ok := visited[pointer]; ok {
+		return false
+	}
+	visited[pointer] = struct{}{}
+	return true
+}
+
+func (r *Redactor) structAttrs(rv reflect.Value, visited map[uintptr]struct{}, depth int) []slog.Attr {
+	_type := rv.Type()
+	attrs := make([]slog.Attr, 0, _type.NumField())
+
+	for i := range _type.NumField() {
+		field := _type.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				key = name
+			}
+		}
+
+		if field.Tag.Get(RedactTag) == redactValue {
+			attrs = append(attrs, slog.String(key, r.mask))
+			continue
+		}
+
+		attrs = append(attrs, r.nestedAttr(key, rv.Field(i).Interface(), visited, depth))
+	}
+
+	return attrs
+}
+
+func (r *Redactor) mapAttrs(rv reflect.Value, visited map[uintptr]struct{}, depth int) []slog.Attr {
+	keys := make([]string, 0, rv.Len())
+	values := make(map[string]any, rv.Len())
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		keys = append(keys, key)
+		values[key] = iter.Value().Interface()
+	}
+	slices.Sort(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, r.nestedAttr(key, values[key], visited, depth))
+	}
+	return attrs
+}
+
+// sliceValues expands the elements. There are no list values in slog, so the elements
+// are turned back into plain values and do not go through ReplaceAttr.
+func (r *Redactor) sliceValues(rv reflect.Value, visited map[uintptr]struct{}, depth int) []any {
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = r.plainValue(r.redactValue(rv.Index(i).Interface(), visited, depth+1))
+	}
+	return values
+}
+
+// plainValue turns groups into maps which keys are already matched, and masks
+// patterns in strings, which ReplaceAttr would otherwise do.
+func (r *Redactor) plainValue(value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindGroup:
+		attrs := value.Group()
+		values := make(map[string]any, len(attrs))
+		for _, attr := range attrs {
+			values[attr.Key] = r.plainValue(attr.Value)
+		}
+		return values
+
+	case slog.KindString:
+		return r.redactString(value.String())
+	}
+	return value.Any()
+}
+
+// nestedAttr expands a field or map value right away, handlers do not pass
+// group attributes to ReplaceAttr, so their keys are matched here.
+func (r *Redactor) nestedAttr(key string, value any, visited map[uintptr]struct{}, depth int) slog.Attr {
+	if r.matchKey(key) {
+		return slog.String(key, r.mask)
+	}
+	return slog.Attr{Key: key, Value: r.redactValue(value, visited, depth+1)}
+}
+
+// needsRedaction reports whether the struct type has fields which may need masking,
+// or the slice and array type has elements which may.
+func (r *Redactor) needsRedaction(_type reflect.Type) bool {
+	if cached, ok := r.types.Load(_type); ok {
+		return cached.(bool)
+	}
+	needs := r.scanField(_type, make(map[reflect.Type]struct{}))
+	r.types.Store(_type, needs)
+	return needs
+}
+
+// scanStruct walks the struct fields, visited prevents infinite recursion on self-referencing types.
+func (r *Redactor) scanStruct(_type reflect.Type, visited map[reflect.Type]struct{}) bool {
+	if _, ok := visited[_type]; ok || isMarshaler(_type) {
+		return false
+	}
+	visited[_type] = struct{}{}
+
+	for i := range _type.NumField() {
+		field := _type.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Tag.Get(RedactTag) == redactValue || r.matchKey(field.Name) || r.scanField(field.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) scanField(_type reflect.Type, visited map[reflect.Type]struct{}) bool {
+	// elements of slices and arrays are walked the way pointed values are
+	for _type.Kind() == reflect.Pointer || _type.Kind() == reflect.Slice || _type.Kind() == reflect.Array {
+		if isMarshaler(_type) {
+			return false
+		}
+		_type = _type.Elem()
+	}
+
+	switch _type.Kind() {
+	case reflect.Struct:
+		return r.scanStruct(_type, visited)
+	case reflect.Map, reflect.Interface:
+		return true
+	case reflect.String:
+		return len(r.patterns) > 0
+	}
+	return false
+}
+
+func isMarshaler(_type reflect.Type) bool {
+	return _type.Implements(jsonMarshalerType) || _type.Implements(textMarshalerType) ||
+		reflect.PointerTo(_type).Implements(jsonMarshalerType) || reflect.PointerTo(_type).Implements(textMarshalerType)
+}
@@ -0,0 +1,180 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/config"
+)
+
+type testAccount struct {
+	Login    string
+	Email    string `json:"email"`
+	Secret   string `log:"redact"`
+	Internal string `json:"-"`
+	Creds    *config.Credentials
+	Created  time.Time
+}
+
+func Test_Redaction(t *testing.T) {
+
+	account := testAccount{
+		Login:    "john",
+		Email:    "john@example.com",
+		Secret:   "secret",
+		Internal: "internal",
+		Creds:    &config.Credentials{User: "admin", Password: "qwerty"},
+		Created:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		opts []HandlerOption
+	}{
+		{"json output", []HandlerOption{WithJSONOutput()}},
+		{"pretty json", nil},
+		{"pretty yaml", []HandlerOption{WithYamlMarshaller()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			buf := &bytes.Buffer{}
+			opts := append([]HandlerOption{
+				WithWriter(buf),
+				WithRedaction(
+					RedactKeys("API-Key"),
+					RedactPatterns(CardNumberRegex, EmailRegex),
+				),
+			}, tt.opts...)
+
+			logger := slog.New(NewHandler(opts...)).With(slog.String("Authorization", "Bearer abc"))
+			logger.WithGroup("request").Info("msg",
+				slog.String("access_token", "token-value"),
+				slog.String("x-api-key", "key-value"),
+				slog.String("comment", "card 4111 1111 1111 1111 paid"),
+				slog.Any("account", account),
+				slog.Any("headers", map[string]string{"Authorization": "Basic xyz", "Accept": "*/*"}),
+			)
+
+			out := buf.String()
+			for _, secret := range []string{"Bearer abc", "token-value", "key-value", "4111", "john@example.com", "secret", "internal", "qwerty", "xyz"} {
+				assert.NotContains(t, out, secret)
+			}
+			for _, visible := range []string{"john", "admin", "card", "paid", "*/*", "2025-01-01"} {
+				assert.Contains(t, out, visible)
+			}
+			assert.Contains(t, out, defaultRedactMask)
+		})
+	}
+}
+
+func Test_RedactorReplaceAttr(t *testing.T) {
+
+	r := NewRedactor(RedactMask("***"))
+
+	assert.Equal(t, "***", r.ReplaceAttr(nil, slog.String("Password", "qwerty")).Value.String())
+	assert.Equal(t, "***", r.ReplaceAttr(nil, slog.String("refresh.token", "value")).Value.String())
+	assert.Equal(t, "value", r.ReplaceAttr(nil, slog.String("tokens", "value")).Value.String())
+
+	type plain struct{ Name string }
+	attr := r.ReplaceAttr(nil, slog.Any("plain", plain{Name: "name"}))
+	assert.Equal(t, slog.KindAny, attr.Value.Kind())
+
+	attr = r.ReplaceAttr(nil, slog.Any("creds", config.Credentials{User: "user", Password: "qwerty"}))
+	require.Equal(t, slog.KindGroup, attr.Value.Kind())
+	assert.Equal(t, []slog.Attr{slog.String("User", "user"), slog.String("Password", "***")}, attr.Value.Group())
+}
+
+func Test_RedactionJSONLine(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput(), WithRedaction()))
+	logger.Info("msg", slog.Any("creds", config.Credentials{User: "user", Password: "qwerty"}))
+
+	var record map[string]any
+	require.NoError(t, json.NewDecoder(strings.NewReader(buf.String())).Decode(&record))
+	assert.Equal(t, map[string]any{"User": "user", "Password": defaultRedactMask}, record["creds"])
+}
+
+type testNode struct {
+	Name     string
+	Password string
+	Next     *testNode
+}
+
+func Test_RedactionCycle(t *testing.T) {
+
+	node := &testNode{Name: "first", Password: "p"}
+	node.Next = &testNode{Name: "second", Next: node}
+
+	cyclic := map[string]any{"name": "map"}
+	cyclic["self"] = cyclic
+
+	for _, opts := range [][]HandlerOption{{WithJSONOutput()}, nil, {WithCompactOutput()}} {
+
+		buf := &bytes.Buffer{}
+		logger := slog.New(NewHandler(append([]HandlerOption{WithWriter(buf), WithRedaction()}, opts...)...))
+		logger.Info("cycle", slog.Any("node", node), slog.Any("map", cyclic))
+
+		assert.Contains(t, buf.String(), "second")
+		assert.NotContains(t, buf.String(), `"p"`)
+		// two passwords, the cyclic pointer and the cyclic map
+		assert.Equal(t, 4, strings.Count(buf.String(), defaultRedactMask), buf.String())
+	}
+
+	value := NewRedactor().redactAny(node)
+	require.Equal(t, slog.KindGroup, value.Kind())
+
+	next := value.Group()[2].Value
+	require.Equal(t, slog.KindGroup, next.Kind())
+	assert.Equal(t, slog.StringValue(defaultRedactMask), next.Group()[2].Value)
+}
+
+type testUsers struct {
+	Name  string
+	Users []config.Credentials
+}
+
+func Test_RedactionSlices(t *testing.T) {
+
+	for _, opts := range [][]HandlerOption{{WithJSONOutput()}, nil, {WithYamlMarshaller()}, {WithCompactOutput()}} {
+
+		buf := &bytes.Buffer{}
+		logger := slog.New(NewHandler(append([]HandlerOption{
+			WithWriter(buf),
+			WithRedaction(RedactPatterns(EmailRegex)),
+		}, opts...)...))
+
+		logger.Info("slices",
+			slog.Any("slice", []config.Credentials{{User: "u1", Password: "p1"}}),
+			slog.Any("struct", testUsers{Name: "team", Users: []config.Credentials{{User: "u2", Password: "p2"}}}),
+			slog.Any("maps", []map[string]string{{"password": "p3", "email": "john@example.com"}}),
+			slog.Any("array", [1]*config.Credentials{{User: "u4", Password: "p4"}}),
+			slog.Any("ids", []int{42}),
+		)
+
+		out := buf.String()
+		for _, secret := range []string{"p1", "p2", "p3", "p4", "john@example.com"} {
+			assert.NotContains(t, out, secret, out)
+		}
+		for _, visible := range []string{"u1", "u2", "team", "u4", "42"} {
+			assert.Contains(t, out, visible)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput(), WithRedaction()))
+	logger.Info("msg", slog.Any("users", []config.Credentials{{User: "user", Password: "qwerty"}}))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, []any{map[string]any{"User": "user", "Password": defaultRedactMask}}, record["users"])
+}
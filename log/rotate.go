@@ -0,0 +1,391 @@
+package log
+
+import (
+	// builtin
+	"cmp"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// RotatingFile is an io.WriteCloser writing to a file which is rotated by size and age.
+// Rotated files are renamed to "name-<time>.ext", optionally gzipped,
+// and only the newest of them are kept. It plugs into WithWriter.
+type RotatingFile struct {
+	filename   string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+	now        func() time.Time
+
+	mutex    sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+
+	reopenOn []os.Signal
+	signals  chan os.Signal
+	done     chan struct{}
+	// compression and cleanup of rotated files
+	background sync.WaitGroup
+	cleanup    sync.Mutex
+}
+
+// The signature of the function for setting RotatingFile parameters
+type RotateOption func(*RotatingFile)
+
+// NewRotatingFile opens the file for appending, creating it and its directory if needed.
+func NewRotatingFile(filename string, opts ...RotateOption) (*RotatingFile, error) {
+	f := &RotatingFile{
+		filename: filename,
+		now:      time.Now,
+		done:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	// the watcher starts only for an opened file, so it does not leak on errors
+	if len(f.reopenOn) > 0 {
+		f.signals = make(chan os.Signal, 1)
+		signal.Notify(f.signals, f.reopenOn...)
+		go f.watchSignals(f.signals)
+	}
+
+	return f, nil
+}
+
+// WithMaxSize rotates the file before it grows over size bytes.
+func WithMaxSize(size int64) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxSize = size
+	}
+}
+
+// WithMaxAge rotates the file once it has been written to for longer than age.
+func WithMaxAge(age time.Duration) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxAge = age
+	}
+}
+
+// WithMaxBackups keeps only the newest count rotated files, zero keeps all of them.
+func WithMaxBackups(count int) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxBackups = count
+	}
+}
+
+// WithCompress gzips rotated files.
+func WithCompress() RotateOption {
+	return func(f *RotatingFile) {
+		f.compress = true
+	}
+}
+
+// ReopenOnSignal reopens the file when the process gets one of the signals,
+// SIGHUP if none are given. It lets external tools like logrotate move the file.
+func ReopenOnSignal(signals ...os.Signal) RotateOption {
+	return func(f *RotatingFile) {
+		if len(signals) == 0 {
+			signals = []os.Signal{syscall.SIGHUP}
+		}
+		f.reopenOn = signals
+	}
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	// a failed rotation or reopen left no file, try to open it again
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	// the record is written to the current file if the rotation fails,
+	// the rotation is retried by the next write
+	if f.needsRotation(len(p)) {
+		if err := f.rotate(); err != nil && f.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file regardless of its size and age.
+func (f *RotatingFile) Rotate() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Reopen closes and opens the file again without rotating it.
+func (f *RotatingFile) Reopen() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	if err := f.close(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close closes the file and waits for rotated files to be compressed.
+func (f *RotatingFile) Close() error {
+	f.mutex.Lock()
+	if f.signals != nil {
+		signal.Stop(f.signals)
+		close(f.done)
+		f.signals = nil
+	}
+	f.closed = true
+	err := f.close()
+	f.mutex.Unlock()
+
+	f.background.Wait()
+	return err
+}
+
+func (f *RotatingFile) needsRotation(size int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+int64(size) > f.maxSize {
+		return true
+	}
+	return f.maxAge > 0 && f.now().Sub(f.openedAt) >= f.maxAge
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.filename), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	return nil
+}
+
+func (f *RotatingFile) close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.close(); err != nil {
+		return err
+	}
+
+	backup := f.uniqueBackupName(f.now())
+	if err := os.Rename(f.filename, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// keep writing to the file which could not be moved
+		return errors.Join(err, f.open())
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+
+		f.cleanup.Lock()
+		defer f.cleanup.Unlock()
+
+		if f.compress {
+			compressFile(backup)
+		}
+		f.removeOldBackups()
+	}()
+
+	return nil
+}
+
+func (f *RotatingFile) watchSignals(signals <-chan os.Signal) {
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-signals:
+			f.Reopen()
+		}
+	}
+}
+
+// backupName returns "dir/name-<time>.ext" for "dir/name.ext".
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.filename)
+	prefix := strings.TrimSuffix(f.filename, ext)
+	return fmt.Sprintf("%s-%s%s", prefix, t.Format(backupTimeFormat), ext)
+}
+
+// uniqueBackupName returns backupName or, if a file rotated at the same time exists,
+// "dir/name-<time>-<n>.ext", so that the rename never replaces an older backup.
+func (f *RotatingFile) uniqueBackupName(t time.Time) string {
+	backup := f.backupName(t)
+	ext := filepath.Ext(backup)
+	prefix := strings.TrimSuffix(backup, ext)
+
+	for n := 1; exists(backup) || exists(backup+compressSuffix); n++ {
+		backup = fmt.Sprintf("%s-%d%s", prefix, n, ext)
+	}
+	return backup
+}
+
+func exists(filename string) bool {
+	_, err := os.Lstat(filename)
+	return err == nil
+}
+
+// backups returns rotated files from the oldest to the newest.
+func (f *RotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(f.filename)
+	prefix := strings.TrimSuffix(filepath.Base(f.filename), ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(f.filename))
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		name  string
+		stamp string
+		n     int
+	}
+
+	var found []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix), ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		stamp, counter := stamp[:len(backupTimeFormat)], stamp[len(backupTimeFormat):]
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+
+		var n int
+		if counter != "" {
+			digits, ok := strings.CutPrefix(counter, "-")
+			if n, err = strconv.Atoi(digits); !ok || err != nil || n < 1 {
+				continue
+			}
+		}
+		found = append(found, backup{filepath.Join(filepath.Dir(f.filename), name), stamp, n})
+	}
+
+	slices.SortFunc(found, func(a, b backup) int {
+		return cmp.Or(strings.Compare(a.stamp, b.stamp), cmp.Compare(a.n, b.n))
+	})
+
+	backups := make([]string, 0, len(found))
+	for _, b := range found {
+		backups = append(backups, b.name)
+	}
+	return backups, nil
+}
+
+func (f *RotatingFile) removeOldBackups() {
+	if f.maxBackups <= 0 {
+		return
+	}
+
+	backups, err := f.backups()
+	if err != nil || len(backups) <= f.maxBackups {
+		return
+	}
+
+	for _, backup := range backups[:len(backups)-f.maxBackups] {
+		os.Remove(backup)
+	}
+}
+
+// compressFile replaces the file with its gzipped copy.
+func compressFile(filename string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filename+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(filename + compressSuffix)
+		return err
+	}
+
+	if err := errors.Join(gz.Close(), dst.Close()); err != nil {
+		os.Remove(filename + compressSuffix)
+		return err
+	}
+
+	return os.Remove(filename)
+}
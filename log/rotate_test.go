@@ -0,0 +1,155 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RotatingFileSize(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	f, err := NewRotatingFile(filename, WithMaxSize(10), WithMaxBackups(2), WithCompress())
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	current, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth\n", string(current))
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	for i, expect := range []string{"second\n", "third\n"} {
+		assert.True(t, strings.HasSuffix(backups[i], ".log.gz"))
+		assert.Equal(t, expect, readGzip(t, backups[i]))
+	}
+
+	_, err = f.Write([]byte("closed"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func Test_RotatingFileAge(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+
+	f, err := NewRotatingFile(filename, WithMaxAge(time.Hour))
+	require.NoError(t, err)
+
+	now := time.Now()
+	f.now = func() time.Time { return now }
+
+	f.Write([]byte("old\n"))
+	now = now.Add(time.Hour)
+	f.Write([]byte("new\n"))
+	require.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "old\n", string(backup))
+}
+
+func readGzip(t *testing.T, filename string) string {
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func Test_RotatingFileFailures(t *testing.T) {
+
+	// the backup name is too long for the file system, so the rename fails
+	filename := filepath.Join(t.TempDir(), strings.Repeat("a", 240)+".log")
+
+	f, err := NewRotatingFile(filename, WithMaxSize(10))
+	require.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	assert.Error(t, f.Rotate())
+
+	current, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(current))
+
+	// a directory in place of the file makes the reopen fail
+	require.NoError(t, os.Remove(filename))
+	require.NoError(t, os.Mkdir(filename, 0o755))
+	assert.Error(t, f.Reopen())
+
+	_, err = f.Write([]byte("lost\n"))
+	assert.Error(t, err)
+
+	require.NoError(t, os.Remove(filename))
+	_, err = f.Write([]byte("third\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	current, err = os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(current))
+
+	assert.ErrorIs(t, f.Reopen(), os.ErrClosed)
+}
+
+func Test_RotatingFileSameTime(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+
+	f, err := NewRotatingFile(filename, WithMaxSize(10), WithMaxBackups(3), WithCompress())
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Rotate())
+	require.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+
+	// the first backup of the time got no counter and was removed as the oldest
+	prefix := strings.TrimSuffix(f.backupName(now), ".log")
+	for i, expect := range []string{"second\n", "third\n", "fourth\n"} {
+		assert.Equal(t, fmt.Sprintf("%s-%d.log.gz", prefix, i+1), backups[i])
+		assert.Equal(t, expect, readGzip(t, backups[i]))
+	}
+}
@@ -0,0 +1,52 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RotatingFileReopenOnSignal(t *testing.T) {
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	moved := filepath.Join(dir, "moved.log")
+
+	f, err := NewRotatingFile(filename, ReopenOnSignal(syscall.SIGUSR1))
+	require.NoError(t, err)
+	defer f.Close()
+
+	f.Write([]byte("before\n"))
+	require.NoError(t, os.Rename(filename, moved))
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filename)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	f.Write([]byte("after\n"))
+
+	current, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(current))
+}
+
+func Test_RotatingFileReopenOnSignalFailure(t *testing.T) {
+
+	f := &RotatingFile{}
+	ReopenOnSignal()(f)
+	assert.Equal(t, []os.Signal{syscall.SIGHUP}, f.reopenOn)
+	assert.Nil(t, f.signals)
+
+	// the file is a directory, so the watcher is never started
+	_, err := NewRotatingFile(t.TempDir(), ReopenOnSignal(syscall.SIGUSR1))
+	assert.Error(t, err)
+}
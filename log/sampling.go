@@ -0,0 +1,233 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	AttrSampledMessage = "sampled_msg"
+	AttrSuppressed     = "suppressed"
+)
+
+// maxSamplingKinds limits the number of kinds counted at once, records of new kinds
+// over the limit are suppressed until the kinds which interval is over are forgotten.
+const maxSamplingKinds = 4096
+
+// SamplingHandler limits records with the same level and message.
+// In every interval it passes the first records of a kind and then one in every thereafter,
+// the rest are counted and reported by a "suppressed N similar messages" record.
+type SamplingHandler struct {
+	next  slog.Handler
+	state *samplingState
+}
+
+// samplingState is shared by handlers derived with WithAttrs and WithGroup.
+type samplingState struct {
+	first      uint64
+	thereafter uint64
+	interval   time.Duration
+	maxKinds   int
+	now        func() time.Time
+
+	mutex    sync.Mutex
+	counters map[samplingKey]*samplingCounter
+	swept    time.Time
+	// records of new kinds suppressed over maxKinds
+	overflow        uint64
+	overflowHandler slog.Handler
+}
+
+type samplingKey struct {
+	level   slog.Level
+	message string
+}
+
+type samplingCounter struct {
+	start      time.Time
+	count      uint64
+	suppressed uint64
+	// the handler the last record of the kind was passed to,
+	// used to write the summary
+	handler slog.Handler
+}
+
+type samplingSummary struct {
+	rec     slog.Record
+	handler slog.Handler
+}
+
+// NewSamplingHandler wraps next so that it gets at most first records of a kind
+// per interval and then one in every thereafter. Zero thereafter drops all of them.
+// A non-positive interval disables sampling, every record is passed to next.
+func NewSamplingHandler(next slog.Handler, first, thereafter uint64, interval time.Duration) *SamplingHandler {
+	return &SamplingHandler{
+		next: next,
+		state: &samplingState{
+			first:      first,
+			thereafter: thereafter,
+			interval:   interval,
+			maxKinds:   maxSamplingKinds,
+			now:        time.Now,
+			counters:   make(map[samplingKey]*samplingCounter),
+		},
+	}
+}
+
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, rec slog.Record) error {
+
+	if h.state.interval <= 0 {
+		return h.next.Handle(ctx, rec)
+	}
+
+	key := samplingKey{level: rec.Level, message: rec.Message}
+	now := h.state.now()
+
+	h.state.mutex.Lock()
+
+	counter, ok := h.state.counters[key]
+
+	var suppressed uint64
+	if ok && now.Sub(counter.start) >= h.state.interval {
+		suppressed = counter.suppressed
+		*counter = samplingCounter{start: now}
+	}
+
+	// kinds which stopped coming are forgotten once per interval, their summaries
+	// are not related to the record and are written without its context
+	var swept []samplingSummary
+	if now.Sub(h.state.swept) >= h.state.interval {
+		swept = h.state.sweep(now, false)
+	}
+
+	if !ok {
+		if len(h.state.counters) >= h.state.maxKinds {
+			h.state.overflow++
+			h.state.overflowHandler = h.next
+			h.state.mutex.Unlock()
+			return writeSummaries(context.Background(), swept)
+		}
+		counter = &samplingCounter{start: now}
+		h.state.counters[key] = counter
+	}
+
+	counter.count++
+	counter.handler = h.next
+	allowed := h.state.allowed(counter.count)
+	if !allowed {
+		counter.suppressed++
+	}
+
+	h.state.mutex.Unlock()
+
+	err := writeSummaries(context.Background(), swept)
+	if suppressed > 0 {
+		err = errors.Join(err, h.next.Handle(ctx, summary(key, suppressed, now)))
+	}
+
+	if !allowed {
+		return err
+	}
+
+	return errors.Join(err, h.next.Handle(ctx, rec))
+}
+
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+// Flush writes summaries of the records suppressed so far and forgets
+// kinds which interval is over.
+func (h *SamplingHandler) Flush(ctx context.Context) error {
+	now := h.state.now()
+
+	h.state.mutex.Lock()
+	summaries := h.state.sweep(now, true)
+	h.state.mutex.Unlock()
+
+	return writeSummaries(ctx, summaries)
+}
+
+// Run calls Flush every interval until ctx is done, so that summaries
+// are written even if records of the kind stop coming.
+func (h *SamplingHandler) Run(ctx context.Context) {
+	if h.state.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.state.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.Flush(context.Background())
+			return
+		case <-ticker.C:
+			h.Flush(ctx)
+		}
+	}
+}
+
+// sweep forgets kinds which interval is over and returns summaries of their suppressed records,
+// or of all suppressed records if flush is set. The caller holds the mutex.
+func (state *samplingState) sweep(now time.Time, flush bool) []samplingSummary {
+	state.swept = now
+
+	var summaries []samplingSummary
+	for key, counter := range state.counters {
+		expired := now.Sub(counter.start) >= state.interval
+		if counter.suppressed > 0 && (flush || expired) {
+			summaries = append(summaries, samplingSummary{summary(key, counter.suppressed, now), counter.handler})
+			counter.suppressed = 0
+		}
+		if expired {
+			delete(state.counters, key)
+		}
+	}
+
+	if state.overflow > 0 {
+		rec := slog.NewRecord(now, slog.LevelWarn, fmt.Sprintf("suppressed %d messages over the sampling limit", state.overflow), 0)
+		rec.AddAttrs(slog.Uint64(AttrSuppressed, state.overflow))
+		summaries = append(summaries, samplingSummary{rec, state.overflowHandler})
+		state.overflow = 0
+	}
+
+	return summaries
+}
+
+func writeSummaries(ctx context.Context, summaries []samplingSummary) error {
+	var err error
+	for _, s := range summaries {
+		err = errors.Join(err, s.handler.Handle(ctx, s.rec))
+	}
+	return err
+}
+
+func (state *samplingState) allowed(count uint64) bool {
+	if count <= state.first {
+		return true
+	}
+	return state.thereafter > 0 && (count-state.first)%state.thereafter == 0
+}
+
+func summary(key samplingKey, suppressed uint64, now time.Time) slog.Record {
+	rec := slog.NewRecord(now, key.level, fmt.Sprintf("suppressed %d similar messages", suppressed), 0)
+	rec.AddAttrs(
+		slog.String(AttrSampledMessage, key.message),
+		slog.Uint64(AttrSuppressed, suppressed),
+	)
+	return rec
+}
@@ -0,0 +1,170 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	context_helper "github.com/vishenosik/web/context"
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_SamplingHandler(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	sampler := NewSamplingHandler(NewHandler(WithWriter(buf), WithJSONOutput()), 2, 3, time.Second)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampler.state.now = func() time.Time { return now }
+
+	logger := slog.New(sampler)
+
+	records := func() []map[string]any {
+		var out []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var record map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			out = append(out, record)
+		}
+		buf.Reset()
+		return out
+	}
+
+	// first 2 pass, then every 3rd: 1, 2, 5, 8
+	for i := 1; i <= 9; i++ {
+		logger.Info("hot", slog.Int("i", i))
+	}
+	logger.Warn("hot")
+	logger.Info("cold")
+
+	logged := records()
+	require.Len(t, logged, 6)
+	for i, expect := range []float64{1, 2, 5, 8} {
+		assert.Equal(t, expect, logged[i]["i"])
+	}
+	assert.Equal(t, "WARN", logged[4]["level"])
+	assert.Equal(t, "cold", logged[5]["msg"])
+
+	// the next interval starts with the summary of the previous one
+	now = now.Add(time.Second)
+	logger.With(AppComponent("api")).Info("hot")
+
+	logged = records()
+	require.Len(t, logged, 2)
+	assert.Equal(t, "suppressed 5 similar messages", logged[0]["msg"])
+	assert.Equal(t, "hot", logged[0][AttrSampledMessage])
+	assert.EqualValues(t, 5, logged[0][AttrSuppressed])
+	assert.Equal(t, "api", logged[1][AttrAppComponent])
+}
+
+func Test_SamplingHandlerFlush(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	sampler := NewSamplingHandler(NewHandler(WithWriter(buf), WithJSONOutput()), 1, 0, time.Minute)
+	logger := slog.New(sampler)
+
+	for range 4 {
+		logger.Error("failed")
+	}
+	buf.Reset()
+
+	require.NoError(t, sampler.Flush(context.Background()))
+	assert.Contains(t, buf.String(), `"msg":"suppressed 3 similar messages"`)
+
+	buf.Reset()
+	require.NoError(t, sampler.Flush(context.Background()))
+	assert.Empty(t, buf.String())
+}
+
+func Test_SamplingHandlerKinds(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	sampler := NewSamplingHandler(NewHandler(WithWriter(buf), WithJSONOutput()), 1, 0, time.Second)
+	sampler.state.maxKinds = 3
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampler.state.now = func() time.Time { return now }
+
+	logger := slog.New(sampler)
+
+	for i := range 5 {
+		logger.Info(fmt.Sprintf("user %d logged in", i))
+	}
+	assert.Len(t, sampler.state.counters, 3)
+	assert.Equal(t, 3, strings.Count(buf.String(), "logged in"))
+	buf.Reset()
+
+	// kinds which interval is over are forgotten without Flush
+	now = now.Add(time.Second)
+	logger.Info("user 5 logged in")
+	assert.Len(t, sampler.state.counters, 1)
+	assert.Contains(t, buf.String(), `"msg":"suppressed 2 messages over the sampling limit"`)
+	assert.Contains(t, buf.String(), `"msg":"user 5 logged in"`)
+}
+
+func Test_SamplingHandlerSummaryContext(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	sampler := NewSamplingHandler(NewHandler(WithWriter(buf), WithJSONOutput()), 1, 0, time.Second)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampler.state.now = func() time.Time { return now }
+
+	logger := slog.New(sampler)
+	reqA := context_helper.WithRequestCtx(context.Background(), "req-A")
+	reqB := context_helper.WithRequestCtx(context.Background(), "req-B")
+
+	for range 3 {
+		logger.InfoContext(reqA, "hot")
+		logger.InfoContext(reqB, "own")
+		logger.InfoContext(reqB, "own")
+	}
+	buf.Reset()
+
+	// the summary of another kind is written without the context of the record sweeping it
+	now = now.Add(time.Second)
+	logger.InfoContext(reqB, "own")
+
+	var summaries int
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+		switch record[AttrSampledMessage] {
+		case "hot":
+			summaries++
+			assert.Equal(t, "suppressed 2 similar messages", record["msg"])
+			assert.Nil(t, record[AttrRequestID])
+		case "own":
+			summaries++
+			assert.Equal(t, "suppressed 5 similar messages", record["msg"])
+			assert.Equal(t, "req-B", record[AttrRequestID])
+		}
+	}
+	assert.Equal(t, 2, summaries, buf.String())
+}
+
+func Test_SamplingHandlerWithoutInterval(t *testing.T) {
+
+	records := logtest.NewHandler(slog.LevelInfo)
+	sampler := NewSamplingHandler(records, 1, 0, 0)
+
+	for range 3 {
+		slog.New(sampler).Info("hot")
+	}
+	logtest.AssertMessages(t, records, "hot", "hot", "hot")
+
+	// returns right away instead of panicking in time.NewTicker
+	sampler.Run(context.Background())
+}
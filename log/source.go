@@ -0,0 +1,177 @@
+package log
+
+import (
+	// builtin
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	// pkg
+	pkg_errors "github.com/pkg/errors"
+)
+
+const (
+	AttrStack = "stack"
+
+	maxStackDepth = 64
+)
+
+// stackTracer is implemented by errors created or wrapped by github.com/pkg/errors.
+type stackTracer interface {
+	StackTrace() pkg_errors.StackTrace
+}
+
+// WithSource adds the file, line and function of the logging call to every record.
+func WithSource() HandlerOption {
+	return func(h *Handler) {
+		h.addSource = true
+	}
+}
+
+// WithStackTrace attaches a stack trace to records at or above the level.
+// If the record holds an error carrying a stack from github.com/pkg/errors,
+// that stack is used, otherwise the stack of the logging call is captured.
+func WithStackTrace(level slog.Level) HandlerOption {
+	return func(h *Handler) {
+		h.stackLevel = &level
+	}
+}
+
+// source formats the location of the logging call as "dir/file.go:line pkg.Func".
+func source(rec slog.Record) string {
+	if rec.PC == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+	return formatFrame(frame)
+}
+
+// stackAttrs returns the stack attribute if the level of the record requires one.
+func (h *Handler) stackAttrs(rec slog.Record) []slog.Attr {
+	if h.stackLevel == nil || rec.Level < *h.stackLevel {
+		return nil
+	}
+
+	stack := errorStack(rec)
+	if stack == nil {
+		stack = callerStack(rec.PC)
+	}
+
+	if len(stack) == 0 {
+		return nil
+	}
+	return []slog.Attr{slog.Any(AttrStack, formatStack(stack))}
+}
+
+// errorStack returns the deepest stack stored in an error attribute of the record.
+func errorStack(rec slog.Record) []uintptr {
+	var stack []uintptr
+	rec.Attrs(func(attr slog.Attr) bool {
+		stack = attrStack(attr)
+		return stack == nil
+	})
+	return stack
+}
+
+func attrStack(attr slog.Attr) []uintptr {
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		for _, groupAttr := range attr.Value.Group() {
+			if stack := attrStack(groupAttr); stack != nil {
+				return stack
+			}
+		}
+
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return ErrorStack(err)
+		}
+
+	case slog.KindLogValuer:
+		if value, ok := attr.Value.Any().(errorValue); ok {
+			return ErrorStack(value.err)
+		}
+	}
+	return nil
+}
+
+// ErrorStack returns program counters of the deepest stack
+// stored in the chain of errors created by github.com/pkg/errors.
+func ErrorStack(err error) []uintptr {
+	var stack []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if tracer, ok := err.(stackTracer); ok {
+			trace := tracer.StackTrace()
+			stack = make([]uintptr, len(trace))
+			for i := range trace {
+				stack[i] = uintptr(trace[i])
+			}
+		}
+	}
+	return stack
+}
+
+// callerStack captures the current stack starting from the logging call at pc,
+// so that frames of slog and handlers are left out.
+func callerStack(pc uintptr) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	for i := range pcs {
+		if pcs[i] == pc {
+			return pcs[i:]
+		}
+	}
+	return pcs
+}
+
+// PanicStack returns the stack attribute of a panic, called from the deferred function
+// recovering it. The stack starts at the function which panicked, even if deferred
+// functions on the way recovered and panicked again.
+func PanicStack() slog.Attr {
+	pcs := make([]uintptr, maxStackDepth)
+	stack := formatStack(pcs[:runtime.Callers(2, pcs)])
+
+	// the first panic is the deepest one
+	for i := len(stack) - 1; i >= 0; i-- {
+		if frameFunction(stack[i]) == "runtime.gopanic" {
+			stack = stack[i+1:]
+			break
+		}
+	}
+
+	// runtime frames raising panics like nil dereferences
+	for len(stack) > 1 && strings.HasPrefix(frameFunction(stack[0]), "runtime.") {
+		stack = stack[1:]
+	}
+	return slog.Any(AttrStack, stack)
+}
+
+// frameFunction returns the function of a frame formatted by formatFrame.
+func frameFunction(frame string) string {
+	return frame[strings.LastIndexByte(frame, ' ')+1:]
+}
+
+func formatStack(stack []uintptr) []string {
+	out := make([]string, 0, len(stack))
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "runtime.goexit" {
+			out = append(out, formatFrame(frame))
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+func formatFrame(frame runtime.Frame) string {
+	file := filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File))
+	function := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+	return fmt.Sprintf("%s:%d %s", file, frame.Line, function)
+}
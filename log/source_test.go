@@ -0,0 +1,88 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Source(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithSource()))
+
+	logger.Info("msg")
+
+	assert.Contains(t, buf.String(), "(log/source_test.go:")
+	assert.Contains(t, buf.String(), " log.Test_Source): ")
+}
+
+func Test_StackTrace(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput(), WithStackTrace(slog.LevelError)))
+
+	stack := func() []any {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		buf.Reset()
+		stack, _ := record[AttrStack].([]any)
+		return stack
+	}
+
+	logger.Warn("no stack")
+	assert.Empty(t, stack())
+
+	logger.Error("caller stack")
+	callerStack := stack()
+	require.NotEmpty(t, callerStack)
+	assert.Contains(t, callerStack[0], "log.Test_StackTrace")
+
+	logger.Error("error stack", slog.Group("request", slog.Any(AttrError, newStackError())))
+	errorStack := stack()
+	require.NotEmpty(t, errorStack)
+	assert.Contains(t, errorStack[0], "log.newStackError")
+}
+
+func Test_ErrorStack(t *testing.T) {
+
+	assert.Nil(t, ErrorStack(nil))
+	assert.Nil(t, ErrorStack(io.EOF))
+
+	stack := formatStack(ErrorStack(errors.Wrap(newStackError(), "wrapped")))
+	require.NotEmpty(t, stack)
+	assert.True(t, strings.HasPrefix(stack[0], "log/source_test.go:"))
+	assert.Contains(t, stack[0], "log.newStackError")
+}
+
+func newStackError() error {
+	return errors.New("stack error")
+}
+
+func Test_PanicStack(t *testing.T) {
+
+	var attr slog.Attr
+	func() {
+		defer func() {
+			recover()
+			attr = PanicStack()
+		}()
+		panicking()
+	}()
+
+	assert.Equal(t, AttrStack, attr.Key)
+	stack := attr.Value.Any().([]string)
+	require.NotEmpty(t, stack)
+	assert.Contains(t, stack[0], "log.panicking")
+}
+
+func panicking() {
+	panic("boom")
+}
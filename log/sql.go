@@ -0,0 +1,413 @@
+package log
+
+import (
+	// builtin
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const (
+	AttrQuery        = "query"
+	AttrRowsAffected = "rows_affected"
+)
+
+// sqlLogger logs the calls of a database/sql driver.
+// Successful calls are logged at the debug level and failed ones at the error level.
+type sqlLogger struct {
+	logger *slog.Logger
+}
+
+// NewSQLDriver wraps the driver so that queries, statements and transactions
+// are logged with their duration. Records are logged with the context of the call,
+// so they carry the request ID and the trace of the request:
+//
+//	sql.Register("postgres+log", log.NewSQLDriver(&pq.Driver{}, logger))
+func NewSQLDriver(d driver.Driver, logger *slog.Logger) driver.Driver {
+	return &sqlDriver{driver: d, log: sqlLogger{logger: logger}}
+}
+
+// NewSQLConnector is NewSQLDriver for drivers opened with sql.OpenDB.
+func NewSQLConnector(c driver.Connector, logger *slog.Logger) driver.Connector {
+	return &sqlConnector{
+		connector: c,
+		driver:    &sqlDriver{driver: c.Driver(), log: sqlLogger{logger: logger}},
+	}
+}
+
+func (l sqlLogger) log(ctx context.Context, op, query string, start time.Time, err error, attrs ...slog.Attr) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	// database/sql retries calls failed with driver.ErrBadConn on another connection
+	level := slog.LevelDebug
+	if err != nil && !errors.Is(err, driver.ErrBadConn) {
+		level = slog.LevelError
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs = append(attrs, Took(start))
+	if query != "" {
+		attrs = append(attrs, slog.String(AttrQuery, query))
+	}
+	if err != nil {
+		attrs = append(attrs, Error(err))
+	}
+
+	l.logger.LogAttrs(ctx, level, "sql "+op, attrs...)
+}
+
+type sqlDriver struct {
+	driver driver.Driver
+	log    sqlLogger
+}
+
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	start := time.Now()
+	conn, err := d.driver.Open(name)
+	d.log.log(context.Background(), "connect", "", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLConn(conn, d.log), nil
+}
+
+type sqlConnector struct {
+	connector driver.Connector
+	driver    *sqlDriver
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	start := time.Now()
+	conn, err := c.connector.Connect(ctx)
+	c.driver.log.log(ctx, "connect", "", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLConn(conn, c.driver.log), nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// sqlConn implements the optional context interfaces database/sql prefers.
+// Those the wrapped connection lacks fall back to its plain methods or return driver.ErrSkip.
+// driver.ExecerContext and driver.QueryerContext are implemented by the types below only
+// if the wrapped connection implements them, since database/sql checks arguments
+// of connections implementing them without asking prepared statements.
+type sqlConn struct {
+	conn driver.Conn
+	log  sqlLogger
+}
+
+type sqlExecerConn struct{ *sqlConn }
+
+type sqlQueryerConn struct{ *sqlConn }
+
+type sqlExecerQueryerConn struct{ *sqlConn }
+
+func newSQLConn(conn driver.Conn, log sqlLogger) driver.Conn {
+	c := &sqlConn{conn: conn, log: log}
+
+	_, isExecer := conn.(driver.ExecerContext)
+	_, isQueryer := conn.(driver.QueryerContext)
+
+	switch {
+	case isExecer && isQueryer:
+		return sqlExecerQueryerConn{c}
+	case isExecer:
+		return sqlExecerConn{c}
+	case isQueryer:
+		return sqlQueryerConn{c}
+	}
+	return c
+}
+
+func (c sqlExecerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.exec(ctx, query, args)
+}
+
+func (c sqlQueryerConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.query(ctx, query, args)
+}
+
+func (c sqlExecerQueryerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.exec(ctx, query, args)
+}
+
+func (c sqlExecerQueryerConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.query(ctx, query, args)
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.prepare(ctx, query)
+	}
+
+	c.log.log(ctx, "prepare", query, start, err)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := &sqlStmt{stmt: stmt, conn: c, query: query, log: c.log}
+	if converter, ok := stmt.(driver.ColumnConverter); ok {
+		return &sqlConverterStmt{sqlStmt: wrapped, converter: converter}, nil
+	}
+	return wrapped, nil
+}
+
+// prepare is the fallback database/sql uses for connections without driver.ConnPrepareContext.
+func (c *sqlConn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		stmt.Close()
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// begin is the fallback database/sql uses for connections without driver.ConnBeginTx,
+// which cannot start transactions with options.
+func (c *sqlConn) begin(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+
+	tx, err := c.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (c *sqlConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.begin(ctx, opts)
+	}
+
+	c.log.log(ctx, "begin", "", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx, ctx: ctx, log: c.log}, nil
+}
+
+func (c *sqlConn) exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	result, err := c.conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	c.log.log(ctx, "exec", query, start, err, rowsAffected(result, err)...)
+	return result, err
+}
+
+func (c *sqlConn) query(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	c.log.log(ctx, "query", query, start, err)
+	return rows, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type sqlStmt struct {
+	stmt driver.Stmt
+	// the connection the statement is prepared on, database/sql asks it
+	// to check arguments if the statement cannot
+	conn  *sqlConn
+	query string
+	log   sqlLogger
+}
+
+func (s *sqlStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *sqlStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	start := time.Now()
+	result, err := s.stmt.Exec(args)
+	s.log.log(context.Background(), "exec", s.query, start, err, rowsAffected(result, err)...)
+	return result, err
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.stmt.Query(args)
+	s.log.log(context.Background(), "query", s.query, start, err)
+	return rows, err
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.stmt.(driver.StmtExecContext)
+	if !ok {
+		values, err := namedValues(args)
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return s.Exec(values)
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, args)
+	s.log.log(ctx, "exec", s.query, start, err, rowsAffected(result, err)...)
+	return result, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.stmt.(driver.StmtQueryContext)
+	if !ok {
+		values, err := namedValues(args)
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return s.Query(values)
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	s.log.log(ctx, "query", s.query, start, err)
+	return rows, err
+}
+
+func (s *sqlStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return s.conn.CheckNamedValue(value)
+}
+
+// sqlConverterStmt is a statement implementing driver.ColumnConverter.
+// database/sql skips converting arguments of statements which implement it
+// and do not know the number of their arguments, so sqlStmt implements it
+// only for statements which do.
+type sqlConverterStmt struct {
+	*sqlStmt
+	converter driver.ColumnConverter
+}
+
+func (s *sqlConverterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.converter.ColumnConverter(idx)
+}
+
+type sqlTx struct {
+	tx  driver.Tx
+	ctx context.Context
+	log sqlLogger
+}
+
+func (t *sqlTx) Commit() error {
+	start := time.Now()
+	err := t.tx.Commit()
+	t.log.log(t.ctx, "commit", "", start, err)
+	return err
+}
+
+func (t *sqlTx) Rollback() error {
+	start := time.Now()
+	err := t.tx.Rollback()
+	t.log.log(t.ctx, "rollback", "", start, err)
+	return err
+}
+
+func rowsAffected(result driver.Result, err error) []slog.Attr {
+	if err != nil || result == nil {
+		return nil
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil
+	}
+	return []slog.Attr{slog.Int64(AttrRowsAffected, rows)}
+}
+
+// namedValues converts arguments for drivers without the context interfaces,
+// which do not support named parameters.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,242 @@
+package log
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/log/logtest"
+)
+
+var errSyntax = errors.New("syntax error")
+
+// fakeConn is a connection with the context interfaces.
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "broken" {
+		return nil, errSyntax
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+// plainConn is a connection without the optional interfaces,
+// so database/sql prepares statements.
+type plainConn struct{}
+
+func (plainConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (plainConn) Close() error                              { return nil }
+func (plainConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	done bool
+}
+
+func (*fakeRows) Columns() []string { return []string{"id"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+type fakeConnector struct {
+	conn driver.Conn
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{c.conn} }
+
+type fakeDriver struct {
+	conn driver.Conn
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return d.conn, nil }
+
+func Test_SQLConnector(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+	db := sql.OpenDB(NewSQLConnector(fakeConnector{fakeConn{}}, logger))
+	defer db.Close()
+
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "UPDATE users SET name = $1", "john")
+	require.NoError(t, err)
+
+	var id int64
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT id FROM users").Scan(&id))
+	assert.Equal(t, int64(1), id)
+
+	_, err = db.ExecContext(ctx, "broken")
+	assert.ErrorIs(t, err, errSyntax)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	logtest.AssertMessages(t, records, "sql connect", "sql exec", "sql query", "sql exec", "sql begin", "sql commit")
+	logtest.AssertLogged(t, records, slog.LevelDebug, "sql exec",
+		slog.String(AttrQuery, "UPDATE users SET name = $1"),
+		slog.Int64(AttrRowsAffected, 2),
+	)
+	logtest.AssertLogged(t, records, slog.LevelError, "sql exec", slog.String(AttrQuery, "broken"))
+}
+
+func Test_SQLDriver(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+	sql.Register("log-test-plain", NewSQLDriver(fakeDriver{plainConn{}}, logger))
+
+	db, err := sql.Open("log-test-plain", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM users")
+	require.NoError(t, err)
+
+	rows, err := db.Query("SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	logtest.AssertMessages(t, records, "sql connect", "sql prepare", "sql exec", "sql prepare", "sql query")
+	logtest.AssertLogged(t, records, slog.LevelDebug, "sql exec",
+		slog.String(AttrQuery, "DELETE FROM users"),
+		slog.Int64(AttrRowsAffected, 1),
+	)
+}
+
+// customArg is an argument type only the drivers below accept.
+type customArg struct {
+	id int64
+}
+
+// checkerConn accepts customArg like drivers with a checker on the connection.
+type checkerConn struct {
+	plainConn
+}
+
+func (checkerConn) CheckNamedValue(value *driver.NamedValue) error {
+	if arg, ok := value.Value.(customArg); ok {
+		value.Value = arg.id
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// converterConn prepares statements converting customArg with driver.ColumnConverter.
+type converterConn struct {
+	plainConn
+}
+
+func (converterConn) Prepare(query string) (driver.Stmt, error) {
+	return converterStmt{}, nil
+}
+
+type converterStmt struct {
+	fakeStmt
+}
+
+func (converterStmt) NumInput() int {
+	return 1
+}
+
+func (converterStmt) ColumnConverter(int) driver.ValueConverter {
+	return customConverter{}
+}
+
+type customConverter struct{}
+
+func (customConverter) ConvertValue(value any) (driver.Value, error) {
+	if arg, ok := value.(customArg); ok {
+		return arg.id, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+func Test_SQLArgumentCheckers(t *testing.T) {
+
+	for name, conn := range map[string]driver.Conn{
+		"conn checker":     checkerConn{},
+		"column converter": converterConn{},
+	} {
+		t.Run(name, func(t *testing.T) {
+
+			logger, _ := logtest.NewLogger()
+			db := sql.OpenDB(NewSQLConnector(fakeConnector{conn}, logger))
+			defer db.Close()
+
+			stmt, err := db.Prepare("DELETE FROM users WHERE id = $1")
+			require.NoError(t, err)
+			defer stmt.Close()
+
+			_, err = stmt.Exec(customArg{id: 1})
+			assert.NoError(t, err)
+
+			_, err = db.Exec("DELETE FROM users WHERE id = $1", customArg{id: 1})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_SQLBeginTxOptions(t *testing.T) {
+
+	logger, _ := logtest.NewLogger()
+	db := sql.OpenDB(NewSQLConnector(fakeConnector{plainConn{}}, logger))
+	defer db.Close()
+
+	ctx := context.Background()
+
+	_, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	assert.EqualError(t, err, "sql: driver does not support non-default isolation level")
+
+	_, err = db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	assert.EqualError(t, err, "sql: driver does not support read-only transactions")
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+}
+
+func Test_SQLPrepareCanceled(t *testing.T) {
+
+	logger, _ := logtest.NewLogger()
+	conn := newSQLConn(plainConn{}, sqlLogger{logger: logger}).(driver.ConnPrepareContext)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := conn.PrepareContext(ctx, "SELECT 1")
+	assert.ErrorIs(t, err, context.Canceled)
+}
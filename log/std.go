@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 )
@@ -15,8 +16,9 @@ func NewStdLogger(logger *slog.Logger) *stdLogger {
 	}
 }
 
+// Fatalf logs the message at LevelFatal and exits, as goose expects.
 func (sl *stdLogger) Fatalf(format string, v ...any) {
-	sl.logger.Error(fmt.Sprintf(format, v...))
+	fatal(context.Background(), sl.logger, fmt.Sprintf(format, v...))
 }
 
 func (sl *stdLogger) Printf(format string, v ...any) {
@@ -0,0 +1,68 @@
+package log
+
+import (
+	// builtin
+	"log/slog"
+
+	// internal
+	"github.com/vishenosik/web/colors"
+)
+
+// Theme sets the styles of the pretty output elements.
+type Theme struct {
+	Time      colors.Style
+	Component colors.Style
+	Message   colors.Style
+	Source    colors.Style
+	Levels    map[slog.Level]colors.Style
+
+	// compact output attributes
+	Key    colors.Style
+	String colors.Style
+	Number colors.Style
+	Bool   colors.Style
+	Error  colors.Style
+}
+
+// DefaultTheme returns the theme the handler uses unless WithTheme is given.
+func DefaultTheme() Theme {
+	return Theme{
+		Component: colors.NewStyle(colors.Basic(colors.Green)),
+		Message:   colors.NewStyle(colors.Basic(colors.Cyan)),
+		Source:    colors.NewStyle(colors.Palette(245)),
+		Levels: map[slog.Level]colors.Style{
+			slog.LevelDebug: colors.NewStyle(colors.Basic(colors.Magenta)),
+			slog.LevelInfo:  colors.NewStyle(colors.Basic(colors.Blue)),
+			slog.LevelWarn:  colors.NewStyle(colors.Basic(colors.Yellow)),
+			slog.LevelError: colors.NewStyle(colors.Basic(colors.Red)),
+		},
+		Key:    colors.NewStyle(colors.Palette(245)),
+		String: colors.NewStyle(colors.Basic(colors.Green)),
+		Number: colors.NewStyle(colors.Basic(colors.Magenta)),
+		Bool:   colors.NewStyle(colors.Basic(colors.Yellow)),
+		Error:  colors.NewStyle(colors.Basic(colors.Red)),
+	}
+}
+
+// WithTheme sets the styles of the pretty output.
+func WithTheme(theme Theme) HandlerOption {
+	return func(h *Handler) {
+		h.theme = theme
+	}
+}
+
+// WithColor turns ANSI colors on or off. By default colors are used
+// only if the writer is a terminal and NO_COLOR is not set.
+func WithColor(enabled bool) HandlerOption {
+	return func(h *Handler) {
+		h.color = &enabled
+	}
+}
+
+// paint applies the style if colors are enabled.
+func (h *Handler) paint(style colors.Style, text string) string {
+	if !h.colored {
+		return text
+	}
+	return style.Sprint(text)
+}
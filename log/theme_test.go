@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vishenosik/web/colors"
+)
+
+func Test_Theme(t *testing.T) {
+
+	theme := DefaultTheme()
+	theme.Message = colors.Style{Color: colors.RGB(1, 2, 3), Bold: true}
+	theme.Levels[slog.LevelInfo] = colors.Style{Color: colors.Palette(33), Underline: true}
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithTheme(theme), WithColor(true)))
+	logger.Info("msg")
+
+	assert.Contains(t, buf.String(), "\x1b[4;38;5;33mINFO\x1b[0m: \x1b[1;38;2;1;2;3mmsg\x1b[0m\n")
+}
+
+func Test_ColorDetection(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithNumbersHighlight(colors.Red)))
+	logger.With(AppComponent("api")).Error("msg", slog.Int("code", 500))
+
+	assert.NotContains(t, buf.String(), "\x1b[")
+
+	t.Setenv(colors.NoColorEnv, "1")
+	h := NewHandler()
+	assert.False(t, h.colored)
+}
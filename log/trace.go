@@ -0,0 +1,69 @@
+package log
+
+import (
+	// builtin
+	"context"
+	"log/slog"
+
+	// internal
+	context_helper "github.com/vishenosik/web/context"
+)
+
+const (
+	AttrTraceID = "trace_id"
+	AttrSpanID  = "span_id"
+)
+
+// TraceExtractor reads the trace and span IDs of the current span from a context.
+// Implement it to correlate logs with any tracing library without depending on it,
+// for example with OpenTelemetry:
+//
+//	TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+//	    span := trace.SpanContextFromContext(ctx)
+//	    return span.TraceID().String(), span.SpanID().String(), span.IsValid()
+//	})
+type TraceExtractor interface {
+	Trace(ctx context.Context) (traceID, spanID string, ok bool)
+}
+
+// TraceExtractorFunc is a function implementing TraceExtractor.
+type TraceExtractorFunc func(ctx context.Context) (traceID, spanID string, ok bool)
+
+func (f TraceExtractorFunc) Trace(ctx context.Context) (string, string, bool) {
+	return f(ctx)
+}
+
+// W3CTraceExtractor reads the traceparent stored with context.WithTraceParent.
+// Handler uses it by default.
+var W3CTraceExtractor TraceExtractor = TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+	traceCtx, ok := context_helper.TraceCtx(ctx)
+	if !ok {
+		return "", "", false
+	}
+	return traceCtx.TraceID(), traceCtx.SpanID(), true
+})
+
+// TraceAttrs returns the trace_id and span_id attributes of the context, if it has a trace.
+func TraceAttrs(ctx context.Context, extractor TraceExtractor) []slog.Attr {
+	if ctx == nil || extractor == nil {
+		return nil
+	}
+
+	traceID, spanID, ok := extractor.Trace(ctx)
+	if !ok || traceID == "" {
+		return nil
+	}
+
+	attrs := []slog.Attr{slog.String(AttrTraceID, traceID)}
+	if spanID != "" {
+		attrs = append(attrs, slog.String(AttrSpanID, spanID))
+	}
+	return attrs
+}
+
+// WithTraceExtractor replaces the default W3CTraceExtractor of the handler.
+func WithTraceExtractor(extractor TraceExtractor) HandlerOption {
+	return func(h *Handler) {
+		h.trace = extractor
+	}
+}
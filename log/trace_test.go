@@ -0,0 +1,51 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	context_helper "github.com/vishenosik/web/context"
+)
+
+func Test_TraceAttrs(t *testing.T) {
+
+	ctx, err := context_helper.WithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput()))
+	logger.InfoContext(ctx, "msg")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record[AttrTraceID])
+	assert.Equal(t, "00f067aa0ba902b7", record[AttrSpanID])
+
+	assert.Nil(t, TraceAttrs(context.Background(), W3CTraceExtractor))
+	assert.Nil(t, TraceAttrs(ctx, nil))
+}
+
+func Test_TraceExtractor(t *testing.T) {
+
+	type spanKey struct{}
+
+	extractor := TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+		span, ok := ctx.Value(spanKey{}).(string)
+		return "trace", span, ok
+	})
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewHandler(WithWriter(buf), WithJSONOutput(), WithTraceExtractor(extractor)))
+	logger.InfoContext(context.WithValue(context.Background(), spanKey{}, "span"), "msg")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "trace", record[AttrTraceID])
+	assert.Equal(t, "span", record[AttrSpanID])
+}
@@ -0,0 +1,90 @@
+package log
+
+import (
+	// builtin
+	"context"
+	stdlog "log"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// levelAliases are level names libraries use which ParseLevel does not know.
+var levelAliases = map[string]slog.Level{
+	"warning": slog.LevelWarn,
+	"err":     slog.LevelError,
+	"crit":    LevelCritical,
+	"panic":   LevelFatal,
+}
+
+// LevelWriter is an io.Writer logging every write as a record.
+// A level the message starts with, like "[WARN] ...", "error: ..." or an uppercase "DEBUG ...",
+// sets the level of the record and is cut from the message.
+type LevelWriter struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// NewLevelWriter returns a LevelWriter logging messages without a level at the level.
+func NewLevelWriter(logger *slog.Logger, level slog.Level) *LevelWriter {
+	return &LevelWriter{
+		logger: logger,
+		level:  level,
+	}
+}
+
+// NewLogLogger returns a *log.Logger of the standard library writing to the logger,
+// for libraries like net/http which accept one:
+//
+//	server := &http.Server{ErrorLog: log.NewLogLogger(logger, slog.LevelError)}
+func NewLogLogger(logger *slog.Logger, level slog.Level) *stdlog.Logger {
+	return stdlog.New(NewLevelWriter(logger, level), "", 0)
+}
+
+func (lw *LevelWriter) Write(p []byte) (int, error) {
+	ctx := context.Background()
+
+	level, msg := detectLevel(strings.TrimRight(string(p), "\r\n"), lw.level)
+	if !lw.logger.Enabled(ctx, level) {
+		return len(p), nil
+	}
+
+	if err := lw.logger.Handler().Handle(ctx, slog.NewRecord(time.Now(), level, msg, 0)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// detectLevel returns the level the message starts with and the message without it.
+func detectLevel(msg string, fallback slog.Level) (slog.Level, string) {
+	var token, rest string
+
+	if strings.HasPrefix(msg, "[") {
+		end := strings.IndexByte(msg, ']')
+		if end < 0 {
+			return fallback, msg
+		}
+		token, rest = msg[1:end], msg[end+1:]
+	} else {
+		end := strings.IndexAny(msg, ": ")
+		if end < 0 {
+			return fallback, msg
+		}
+		token, rest = msg[:end], msg[end:]
+		// a bare word is a level only in uppercase, "Error connecting" is prose
+		if msg[end] == ' ' && token != strings.ToUpper(token) {
+			return fallback, msg
+		}
+	}
+
+	level, ok := levelAliases[strings.ToLower(token)]
+	if !ok {
+		parsed, err := ParseLevel(token)
+		if err != nil {
+			return fallback, msg
+		}
+		level = parsed
+	}
+
+	return level, strings.TrimLeft(strings.TrimPrefix(rest, ":"), " ")
+}
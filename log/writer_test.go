@@ -0,0 +1,65 @@
+package log
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_DetectLevel(t *testing.T) {
+
+	tests := []struct {
+		msg   string
+		level slog.Level
+		out   string
+	}{
+		{"[WARN] disk almost full", slog.LevelWarn, "disk almost full"},
+		{"[error]: failed", slog.LevelError, "failed"},
+		{"DEBUG cache miss", slog.LevelDebug, "cache miss"},
+		{"warning: deprecated", slog.LevelWarn, "deprecated"},
+		{"TRACE: entered", LevelTrace, "entered"},
+		{"http: TLS handshake error", slog.LevelInfo, "http: TLS handshake error"},
+		{"[unknown] message", slog.LevelInfo, "[unknown] message"},
+		{"[unclosed message", slog.LevelInfo, "[unclosed message"},
+		{"single", slog.LevelInfo, "single"},
+		{"Error connecting to db", slog.LevelInfo, "Error connecting to db"},
+		{"info about the request", slog.LevelInfo, "info about the request"},
+		{"Error: connecting to db", slog.LevelError, "connecting to db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			level, out := detectLevel(tt.msg, slog.LevelInfo)
+			assert.Equal(t, tt.level, level)
+			assert.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func Test_LogLogger(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+	stdLogger := NewLogLogger(logger, slog.LevelError)
+
+	stdLogger.Println("http: panic serving 127.0.0.1")
+	stdLogger.Printf("[INFO] server started on %d", 8080)
+
+	logtest.AssertLogged(t, records, slog.LevelError, "http: panic serving 127.0.0.1")
+	logtest.AssertLogged(t, records, slog.LevelInfo, "server started on 8080")
+}
+
+func Test_LevelWriterEnabled(t *testing.T) {
+
+	records := logtest.NewHandler(slog.LevelWarn)
+	writer := NewLevelWriter(slog.New(records), slog.LevelInfo)
+
+	n, err := writer.Write([]byte("DEBUG: skipped\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 15, n)
+
+	writer.Write([]byte("error: kept\n"))
+	logtest.AssertMessages(t, records, "kept")
+}
@@ -0,0 +1,237 @@
+// Package metrics implements counters, gauges and histograms with labels
+// and writes them in the Prometheus text format, without the client library.
+package metrics
+
+import (
+	// builtin
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// labelSeparator joins label values into series keys, it cannot appear in UTF-8 text.
+const labelSeparator = "\xff"
+
+// family holds the series of a metric by their label values.
+type family[series any] struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	newSeries func() *series
+
+	mutex  sync.RWMutex
+	series map[string]*series
+	values map[string][]string
+}
+
+func newFamily[series any](name, help, kind string, labels []string, newSeries func() *series) family[series] {
+	return family[series]{
+		name:      name,
+		help:      help,
+		kind:      kind,
+		labels:    labels,
+		newSeries: newSeries,
+		series:    make(map[string]*series),
+		values:    make(map[string][]string),
+	}
+}
+
+// get returns the series of the label values, creating it on the first use.
+// It panics if the number of values does not match the labels of the metric.
+func (f *family[series]) get(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+
+	key := seriesKey(values)
+
+	f.mutex.RLock()
+	s, ok := f.series[key]
+	f.mutex.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if s, ok = f.series[key]; !ok {
+		s = f.newSeries()
+		f.series[key] = s
+		f.values[key] = slices.Clone(values)
+	}
+	return s
+}
+
+// each calls fn for every series ordered by the label values.
+func (f *family[series]) each(fn func(values []string, s *series)) {
+	f.mutex.RLock()
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	f.mutex.RUnlock()
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		f.mutex.RLock()
+		s, values := f.series[key], f.values[key]
+		f.mutex.RUnlock()
+		fn(values, s)
+	}
+}
+
+func (f *family[series]) describe() (name, help, kind string) {
+	return f.name, f.help, f.kind
+}
+
+func seriesKey(values []string) string {
+	switch len(values) {
+	case 0:
+		return ""
+	case 1:
+		return values[0]
+	}
+	return strings.Join(values, labelSeparator)
+}
+
+// float is a float64 updated atomically.
+type float struct {
+	bits atomic.Uint64
+}
+
+func (f *float) add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+func (f *float) set(value float64) {
+	f.bits.Store(math.Float64bits(value))
+}
+
+func (f *float) load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// Counter is a value which only goes up, like the number of handled requests.
+type Counter struct {
+	family[float]
+}
+
+// Inc adds one to the series of the label values.
+func (c *Counter) Inc(values ...string) {
+	c.get(values).add(1)
+}
+
+// Add adds delta to the series of the label values. Negative deltas are ignored.
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	c.get(values).add(delta)
+}
+
+// Value returns the value of the series of the label values.
+func (c *Counter) Value(values ...string) float64 {
+	return c.get(values).load()
+}
+
+// Gauge is a value which goes up and down, like the number of requests in flight.
+type Gauge struct {
+	family[float]
+}
+
+// Set sets the series of the label values.
+func (g *Gauge) Set(value float64, values ...string) {
+	g.get(values).set(value)
+}
+
+// Add adds delta to the series of the label values.
+func (g *Gauge) Add(delta float64, values ...string) {
+	g.get(values).add(delta)
+}
+
+// Inc adds one to the series of the label values.
+func (g *Gauge) Inc(values ...string) {
+	g.get(values).add(1)
+}
+
+// Dec subtracts one from the series of the label values.
+func (g *Gauge) Dec(values ...string) {
+	g.get(values).add(-1)
+}
+
+// Value returns the value of the series of the label values.
+func (g *Gauge) Value(values ...string) float64 {
+	return g.get(values).load()
+}
+
+// Histogram counts observations in buckets, like request durations.
+type Histogram struct {
+	family[histogramSeries]
+	buckets []float64
+}
+
+type histogramSeries struct {
+	mutex sync.Mutex
+	// counts[i] is the number of observations in (buckets[i-1], buckets[i]],
+	// the last one counts those above every bucket
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// DefaultBuckets suit durations in seconds of network requests.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Observe adds the value to the series of the label values.
+func (h *Histogram) Observe(value float64, values ...string) {
+	s := h.get(values)
+	i, _ := slices.BinarySearch(h.buckets, value)
+
+	s.mutex.Lock()
+	s.counts[i]++
+	s.count++
+	s.sum += value
+	s.mutex.Unlock()
+}
+
+// Count returns the number of observations of the series of the label values.
+func (h *Histogram) Count(values ...string) uint64 {
+	s := h.get(values)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.count
+}
+
+// Sum returns the sum of observations of the series of the label values.
+func (h *Histogram) Sum(values ...string) float64 {
+	s := h.get(values)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sum
+}
+
+// snapshot returns cumulative bucket counts, the count and the sum.
+func (s *histogramSeries) snapshot() ([]uint64, uint64, float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cumulative := make([]uint64, len(s.counts))
+	var total uint64
+	for i, count := range s.counts {
+		total += count
+		cumulative[i] = total
+	}
+	return cumulative, s.count, s.sum
+}
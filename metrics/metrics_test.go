@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Counter(t *testing.T) {
+
+	registry := NewRegistry()
+	counter := registry.Counter("requests_total", "Handled requests.", "method")
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter.Inc("GET")
+		}()
+	}
+	wg.Wait()
+
+	counter.Add(2.5, "POST")
+	counter.Add(-1, "POST")
+
+	assert.Equal(t, float64(100), counter.Value("GET"))
+	assert.Equal(t, 2.5, counter.Value("POST"))
+	assert.Same(t, counter, registry.Counter("requests_total", "Handled requests.", "method"))
+}
+
+func Test_Gauge(t *testing.T) {
+
+	gauge := NewRegistry().Gauge("in_flight", "Requests in flight.")
+	gauge.Inc()
+	gauge.Inc()
+	gauge.Dec()
+	assert.Equal(t, float64(1), gauge.Value())
+
+	gauge.Set(7)
+	gauge.Add(-2)
+	assert.Equal(t, float64(5), gauge.Value())
+}
+
+func Test_Histogram(t *testing.T) {
+
+	histogram := NewRegistry().Histogram("latency_seconds", "Latency.", []float64{1, 0.1})
+	for _, value := range []float64{0.05, 0.1, 0.5, 2} {
+		histogram.Observe(value)
+	}
+
+	assert.Equal(t, uint64(4), histogram.Count())
+	assert.Equal(t, 2.65, histogram.Sum())
+}
+
+func Test_RegistryConflicts(t *testing.T) {
+
+	registry := NewRegistry()
+	registry.Counter("total", "Total.", "level")
+
+	assert.Panics(t, func() { registry.Gauge("total", "Total.", "level") })
+	assert.Panics(t, func() { registry.Counter("total", "Total.", "code") })
+	assert.Panics(t, func() { registry.Counter("total", "Total.", "level").Inc() })
+}
+
+func Test_WriteText(t *testing.T) {
+
+	registry := NewRegistry()
+
+	counter := registry.Counter("log_records_total", "Logged records\nby level.", "level")
+	counter.Inc("INFO")
+	counter.Add(2, "ERROR")
+
+	registry.Gauge("queue", "Queued records.").Set(3)
+
+	histogram := registry.Histogram("write_seconds", "Write latency.", []float64{0.1, 1}, "path")
+	histogram.Observe(0.1, `C:\logs "main"`)
+	histogram.Observe(5, `C:\logs "main"`)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, registry.WriteText(buf))
+
+	assert.Equal(t, `# HELP log_records_total Logged records\nby level.
+# TYPE log_records_total counter
+log_records_total{level="ERROR"} 2
+log_records_total{level="INFO"} 1
+# HELP queue Queued records.
+# TYPE queue gauge
+queue 3
+# HELP write_seconds Write latency.
+# TYPE write_seconds histogram
+write_seconds_bucket{path="C:\\logs \"main\"",le="0.1"} 1
+write_seconds_bucket{path="C:\\logs \"main\"",le="1"} 1
+write_seconds_bucket{path="C:\\logs \"main\"",le="+Inf"} 2
+write_seconds_sum{path="C:\\logs \"main\""} 5.1
+write_seconds_count{path="C:\\logs \"main\""} 2
+`, buf.String())
+}
+
+func Test_Handler(t *testing.T) {
+
+	registry := NewRegistry()
+	registry.Counter("total", "Total.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "total 1\n")
+}
@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	// builtin
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// metric is a family of series the registry writes.
+type metric interface {
+	describe() (name, help, kind string)
+	write(w *bufio.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text format.
+type Registry struct {
+	mutex   sync.Mutex
+	metrics map[string]metric
+	order   []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]metric),
+	}
+}
+
+// Counter registers a counter or returns the one registered with the name.
+// It panics if the name is taken by another kind of metric or labels.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return register(r, name, kindCounter, labels, func() *Counter {
+		return &Counter{newFamily(name, help, kindCounter, labels, func() *float { return &float{} })}
+	})
+}
+
+// Gauge registers a gauge or returns the one registered with the name.
+// It panics if the name is taken by another kind of metric or labels.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return register(r, name, kindGauge, labels, func() *Gauge {
+		return &Gauge{newFamily(name, help, kindGauge, labels, func() *float { return &float{} })}
+	})
+}
+
+// Histogram registers a histogram with the upper bounds of buckets, DefaultBuckets if nil,
+// or returns the one registered with the name.
+// It panics if the name is taken by another kind of metric or labels.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return register(r, name, kindHistogram, labels, func() *Histogram {
+		newSeries := func() *histogramSeries {
+			return &histogramSeries{counts: make([]uint64, len(buckets)+1)}
+		}
+		return &Histogram{
+			family:  newFamily(name, help, kindHistogram, labels, newSeries),
+			buckets: buckets,
+		}
+	})
+}
+
+func register[M interface {
+	metric
+	labelNames() []string
+}](r *Registry, name, kind string, labels []string, create func() M) M {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if registered, ok := r.metrics[name]; ok {
+		m, ok := registered.(M)
+		if !ok || !slices.Equal(m.labelNames(), labels) {
+			panic(fmt.Sprintf("metrics: %s is already registered as another %s", name, kind))
+		}
+		return m
+	}
+
+	m := create()
+	r.metrics[name] = m
+	r.order = append(r.order, name)
+	return m
+}
+
+// WriteText writes every metric in the Prometheus text format, in the order they were registered.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mutex.Lock()
+	metrics := make([]metric, len(r.order))
+	for i, name := range r.order {
+		metrics[i] = r.metrics[name]
+	}
+	r.mutex.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, m := range metrics {
+		name, help, kind := m.describe()
+		fmt.Fprintf(buf, "# HELP %s %s\n", name, escapeHelp(help))
+		fmt.Fprintf(buf, "# TYPE %s %s\n", name, kind)
+		m.write(buf)
+	}
+	return buf.Flush()
+}
+
+// Handler returns an http.Handler serving the metrics for Prometheus to scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteText(w)
+	})
+}
+
+func (f *family[series]) labelNames() []string {
+	return f.labels
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.each(func(values []string, s *float) {
+		writeSample(w, c.name, c.labels, values, "", "", s.load())
+	})
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.each(func(values []string, s *float) {
+		writeSample(w, g.name, g.labels, values, "", "", s.load())
+	})
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.each(func(values []string, s *histogramSeries) {
+		cumulative, count, sum := s.snapshot()
+		for i, bound := range h.buckets {
+			writeSample(w, h.name+"_bucket", h.labels, values, "le", formatFloat(bound), float64(cumulative[i]))
+		}
+		writeSample(w, h.name+"_bucket", h.labels, values, "le", "+Inf", float64(count))
+		writeSample(w, h.name+"_sum", h.labels, values, "", "", sum)
+		writeSample(w, h.name+"_count", h.labels, values, "", "", float64(count))
+	})
+}
+
+// writeSample writes `name{label="value",...} value`, extraName adds one more label.
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+
+	if len(labels) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, labels[i], values[i])
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	w.WriteString(`="`)
+	w.WriteString(labelEscaper.Replace(value))
+	w.WriteByte('"')
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
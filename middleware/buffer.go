@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/vishenosik/web/api"
+	"github.com/vishenosik/web/log"
+)
+
+// BufferLogs stores a log.LogBuffer in the request context, so that a log.BufferHandler
+// holds the records the request is logged with. The records are written if the request
+// fails with a server error or panics, and dropped otherwise.
+//
+// Only records logged with the request context are buffered:
+//
+//	logger.DebugContext(r.Context(), "cache miss", slog.String("key", key))
+func BufferLogs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		ctx, buffer := log.WithLogBuffer(r.Context())
+		r = r.WithContext(ctx)
+		rw := NewResponseWriter(w)
+
+		// the panic is not recovered, so that its stack stays intact for Recoverer
+		panicked := true
+		defer func() {
+			if panicked || api.IsServerError(rw.Status()) {
+				buffer.Flush()
+				return
+			}
+			buffer.Discard()
+		}()
+
+		next.ServeHTTP(rw, r)
+		panicked = false
+	})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/log"
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_BufferLogs(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		code     int
+		messages []string
+	}{
+		{"success", http.StatusOK, []string{"failure"}},
+		{"client error", http.StatusBadRequest, []string{"failure"}},
+		{"server error", http.StatusServiceUnavailable, []string{"failure", "detail"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			records := logtest.NewHandler(slog.LevelInfo)
+			logger := slog.New(log.NewBufferHandler(records, slog.LevelWarn))
+
+			handler := BufferLogs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logger.DebugContext(r.Context(), "detail")
+				logger.ErrorContext(r.Context(), "failure")
+				w.WriteHeader(tt.code)
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			logtest.AssertMessages(t, records, tt.messages...)
+		})
+	}
+}
+
+func Test_BufferLogsPanic(t *testing.T) {
+
+	records := logtest.NewHandler(slog.LevelInfo)
+	logger := slog.New(log.NewBufferHandler(records, slog.LevelWarn))
+
+	handler := BufferLogs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.InfoContext(r.Context(), "before panic")
+		panic("boom")
+	}))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+
+	logtest.AssertMessages(t, records, "before panic")
+}
+
+func Test_BufferLogsRequestID(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(log.NewBufferHandler(log.NewHandler(log.WithWriter(buf), log.WithJSONOutput()), slog.LevelWarn))
+
+	handler := BufferLogs(RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugContext(r.Context(), "detail")
+		logger.ErrorContext(r.Context(), "failure")
+		w.WriteHeader(http.StatusInternalServerError)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "request-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.Equal(t, "request-1", record[log.AttrRequestID], line)
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/vishenosik/web/log"
+)
+
+// logLevels is the JSON body of the LogLevels handler.
+// A null component level in a PUT request removes the component override.
+type logLevels struct {
+	Level      *logLevel            `json:"level,omitempty"`
+	Components map[string]*logLevel `json:"components,omitempty"`
+}
+
+// logLevel is written by its registered name, so that custom levels read as "TRACE".
+type logLevel slog.Level
+
+func (l logLevel) MarshalText() ([]byte, error) {
+	return []byte(log.LevelName(slog.Level(l))), nil
+}
+
+func (l *logLevel) UnmarshalText(data []byte) error {
+	level, err := log.ParseLevel(string(data))
+	if err != nil {
+		return err
+	}
+	*l = logLevel(level)
+	return nil
+}
+
+// LogLevels returns an http.Handler to read and change levels at runtime.
+//
+// GET responds with the current levels:
+//
+//	{"level":"INFO","components":{"storage":"DEBUG"}}
+//
+// PUT accepts the same body, fields left out are not changed.
+func LogLevels(levels *log.Levels) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.Method {
+		case http.MethodGet:
+
+		case http.MethodPut:
+			var body logLevels
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if body.Level != nil {
+				levels.SetLevel(slog.Level(*body.Level))
+			}
+
+			for component, level := range body.Components {
+				if level == nil {
+					levels.ResetComponentLevel(component)
+					continue
+				}
+				levels.SetComponentLevel(component, slog.Level(*level))
+			}
+
+		default:
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(currentLevels(levels))
+	})
+}
+
+func currentLevels(levels *log.Levels) logLevels {
+	level := logLevel(levels.Level())
+	body := logLevels{
+		Level:      &level,
+		Components: make(map[string]*logLevel),
+	}
+	for component, componentLevel := range levels.Components() {
+		level := logLevel(componentLevel)
+		body.Components[component] = &level
+	}
+	return body
+}
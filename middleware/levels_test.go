@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/log"
+)
+
+func Test_LogLevels(t *testing.T) {
+
+	levels := log.NewLevels(nil)
+	levels.SetComponentLevel("cache", slog.LevelError)
+	handler := LogLevels(levels)
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/log/levels", strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"DEBUG","components":{"cache":"ERROR"}}`, w.Body.String())
+
+	w = serve(http.MethodPut, `{"level":"WARN","components":{"storage":"trace","cache":null}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"WARN","components":{"storage":"TRACE"}}`, w.Body.String())
+	assert.Equal(t, slog.LevelWarn, levels.Level())
+	assert.Equal(t, log.LevelTrace, levels.ComponentLevel("storage"))
+	assert.Equal(t, slog.LevelWarn, levels.ComponentLevel("cache"))
+
+	w = serve(http.MethodPut, `{"level":"LOUD"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(http.MethodPost, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PUT", w.Header().Get("Allow"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(serve(http.MethodGet, "").Body.Bytes(), &body))
+	assert.Equal(t, "WARN", body["level"])
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/vishenosik/web/api"
+	"github.com/vishenosik/web/metrics"
+)
+
+const (
+	// unmatchedRoute labels requests no ServeMux pattern matched,
+	// so that raw paths do not blow up the number of series
+	unmatchedRoute = "unmatched"
+	otherMethod    = "OTHER"
+)
+
+// responseSizeBuckets are upper bounds in bytes, from 100B to 10MB.
+var responseSizeBuckets = []float64{100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000}
+
+// HTTPMetrics are metrics of requests labeled by method, route pattern and status class.
+type HTTPMetrics struct {
+	Requests     *metrics.Counter
+	Duration     *metrics.Histogram
+	InFlight     *metrics.Gauge
+	ResponseSize *metrics.Histogram
+}
+
+// NewHTTPMetrics registers HTTP metrics in the registry, registry.Handler serves them:
+//
+//	mux.Handle("GET /metrics", registry.Handler())
+func NewHTTPMetrics(registry *metrics.Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		Requests:     registry.Counter("http_requests_total", "HTTP requests handled.", "method", "route", "status"),
+		Duration:     registry.Histogram("http_request_duration_seconds", "Duration of HTTP requests.", nil, "method", "route", "status"),
+		InFlight:     registry.Gauge("http_requests_in_flight", "HTTP requests being handled.", "method"),
+		ResponseSize: registry.Histogram("http_response_size_bytes", "Size of HTTP response bodies.", responseSizeBuckets, "method", "route", "status"),
+	}
+}
+
+// Metrics records requests in the metrics. The route is the pattern of the ServeMux route
+// the request matched, so Metrics must wrap the ServeMux directly.
+// Requests which panic are recorded with status 500 unless the response has been started.
+func Metrics(m *HTTPMetrics) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			timeStart := time.Now()
+			rw := NewResponseWriter(w)
+			method := methodLabel(r.Method)
+
+			m.InFlight.Inc(method)
+
+			// the panic is not recovered, so that its stack stays intact for Recoverer
+			panicked := true
+			defer func() {
+				m.InFlight.Dec(method)
+
+				status := rw.Status()
+				if panicked && !rw.Written() {
+					status = http.StatusInternalServerError
+				}
+
+				route := r.Pattern
+				if route == "" {
+					route = unmatchedRoute
+				}
+
+				labels := []string{method, route, api.StatusClass(status)}
+				m.Requests.Inc(labels...)
+				m.Duration.Observe(time.Since(timeStart).Seconds(), labels...)
+				m.ResponseSize.Observe(float64(rw.BytesWritten()), labels...)
+			}()
+
+			next.ServeHTTP(rw, r)
+			panicked = false
+		})
+	}
+}
+
+// methodLabel keeps the standard methods, so that clients cannot add series.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return otherMethod
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/metrics"
+)
+
+func Test_Metrics(t *testing.T) {
+
+	registry := metrics.NewRegistry()
+	m := NewHTTPMetrics(registry)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, float64(1), m.InFlight.Value(http.MethodGet))
+		w.Write([]byte(`{"id":1}`))
+	})
+	mux.HandleFunc("POST /api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	handler := Metrics(m)(mux)
+
+	for _, path := range []string{"/api/v1/users/1", "/api/v1/users/2", "/unknown"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PURGE", "/cache", nil))
+
+	assert.Panics(t, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/users", nil))
+	})
+
+	assert.Equal(t, float64(2), m.Requests.Value(http.MethodGet, "GET /api/v1/users/{id}", "2xx"))
+	assert.Equal(t, float64(1), m.Requests.Value(http.MethodGet, unmatchedRoute, "4xx"))
+	assert.Equal(t, float64(1), m.Requests.Value(otherMethod, unmatchedRoute, "4xx"))
+	assert.Equal(t, float64(1), m.Requests.Value(http.MethodPost, "POST /api/v1/users", "5xx"))
+
+	assert.Equal(t, uint64(2), m.Duration.Count(http.MethodGet, "GET /api/v1/users/{id}", "2xx"))
+	assert.Equal(t, float64(16), m.ResponseSize.Sum(http.MethodGet, "GET /api/v1/users/{id}", "2xx"))
+	assert.Zero(t, m.InFlight.Value(http.MethodGet))
+	assert.Zero(t, m.InFlight.Value(http.MethodPost))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, registry.WriteText(buf))
+	assert.Contains(t, buf.String(), `http_requests_total{method="GET",route="GET /api/v1/users/{id}",status="2xx"} 2`)
+}
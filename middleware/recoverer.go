@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	context_helper "github.com/vishenosik/web/context"
+	"github.com/vishenosik/web/log"
+)
+
+const AttrPanic = "panic"
+
+// PanicReporter sends a recovered panic to an error tracker.
+// The stack is the one debug.Stack returns.
+type PanicReporter func(r *http.Request, recovered any, stack []byte)
+
+type recovererOptions struct {
+	reporter PanicReporter
+}
+
+// The signature of the function for setting Recoverer parameters
+type RecovererOption func(*recovererOptions)
+
+// WithPanicReporter calls the reporter for every recovered panic.
+func WithPanicReporter(reporter PanicReporter) RecovererOption {
+	return func(opts *recovererOptions) {
+		opts.reporter = reporter
+	}
+}
+
+// errorResponse is the JSON body of the 500 response.
+type errorResponse struct {
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Recoverer recovers panics of handlers, logs them with the stack, method, path
+// and request ID, and responds with 500 Internal Server Error, as JSON if the client accepts it.
+// Nothing is written if the handler has already started the response.
+// http.ErrAbortHandler is passed on, so that the server aborts the response quietly.
+func Recoverer(logger *slog.Logger, opts ...RecovererOption) func(next http.Handler) http.Handler {
+
+	options := &recovererOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			rw := NewResponseWriter(w)
+
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				logger.Error("request panicked",
+					slog.String(AttrPanic, fmt.Sprint(recovered)),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					requestIDAttr(r, rw),
+					log.PanicStack(),
+				)
+
+				if options.reporter != nil {
+					options.reporter(r, recovered, debug.Stack())
+				}
+
+				if !rw.Written() {
+					writeInternalError(rw, r)
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+func writeInternalError(w http.ResponseWriter, r *http.Request) {
+	text := http.StatusText(http.StatusInternalServerError)
+
+	if !strings.Contains(r.Header.Get("Accept"), "application/json") {
+		http.Error(w, text, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(errorResponse{
+		Error:     text,
+		RequestID: context_helper.RequestID(r.Context()),
+	})
+}
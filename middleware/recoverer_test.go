@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	context_helper "github.com/vishenosik/web/context"
+	"github.com/vishenosik/web/log"
+	"github.com/vishenosik/web/log/logtest"
+	"github.com/vishenosik/web/metrics"
+)
+
+func Test_Recoverer(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"plain", "text/html", "text/plain; charset=utf-8", "Internal Server Error\n"},
+		{"json", "application/json", "application/json", `{"error":"Internal Server Error","request_id":"req-42"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			logger, records := logtest.NewLogger()
+
+			handler := Recoverer(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var user *struct{ Name string }
+				_ = user.Name
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/users", nil)
+			r.Header.Set("Accept", tt.accept)
+			r = r.WithContext(context_helper.WithRequestCtx(r.Context(), "req-42"))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+
+			assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			assert.Equal(t, tt.contentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, recorder.Body.String())
+
+			logtest.AssertLogged(t, records, slog.LevelError, "request panicked",
+				slog.String("method", http.MethodPost),
+				slog.String("path", "/api/v1/users"),
+				slog.String(log.AttrRequestID, "req-42"),
+			)
+
+			panicValue, _ := records.Records()[0].Attr(AttrPanic)
+			assert.Contains(t, panicValue.String(), "nil pointer dereference")
+
+			stack, ok := records.Records()[0].Attr(log.AttrStack)
+			require.True(t, ok)
+			frames := stack.Any().([]string)
+			require.NotEmpty(t, frames)
+			assert.True(t, strings.HasPrefix(frames[0], "middleware/recoverer_test.go:"), frames[0])
+		})
+	}
+}
+
+func Test_RecovererCommitted(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	var reported any
+	reporter := func(r *http.Request, recovered any, stack []byte) {
+		reported = recovered
+		assert.NotEmpty(t, stack)
+	}
+
+	handler := Recoverer(logger, WithPanicReporter(reporter))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("partial"))
+		panic("boom")
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, "partial", recorder.Body.String())
+	assert.Equal(t, "boom", reported)
+	logtest.AssertLogged(t, records, slog.LevelError, "request panicked", slog.String(AttrPanic, "boom"))
+}
+
+func Test_RecovererAbortHandler(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	handler := Recoverer(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Empty(t, records.Records())
+}
+
+func Test_RecovererNestedPanics(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	// a middleware recovering the panic and panicking again, like some routers do
+	repanic := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					panic(rec)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	handler := Recoverer(logger)(repanic(Metrics(NewHTTPMetrics(metrics.NewRegistry()))(BufferLogs(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+	))))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	stack, ok := records.Records()[0].Attr(log.AttrStack)
+	require.True(t, ok)
+	frames := stack.Any().([]string)
+	require.NotEmpty(t, frames)
+	assert.True(t, strings.HasPrefix(frames[0], "middleware/recoverer_test.go:"), frames[0])
+	assert.NotContains(t, strings.Join(frames, "\n"), "runtime.gopanic")
+}
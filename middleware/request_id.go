@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	context_helper "github.com/vishenosik/web/context"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLength limits IDs taken from clients, which end up in every log line
+	maxRequestIDLength = 128
+)
+
+// RequestID takes the request ID from the X-Request-ID header, or generates a UUID
+// if the header is missing or invalid. The ID is stored with context.WithRequestCtx
+// and written to the X-Request-ID header of the response.
+//
+// Wrap the handlers which log with it, so that log.Handler adds the ID to their records.
+// RequestLogger reads the ID from the response header if it runs first.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(context_helper.WithRequestCtx(r.Context(), requestID)))
+	})
+}
+
+// validRequestID accepts IDs of printable ASCII characters only,
+// so that clients cannot break log lines.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
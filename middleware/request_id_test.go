@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	context_helper "github.com/vishenosik/web/context"
+	"github.com/vishenosik/web/log"
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_RequestID(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		header    string
+		generated bool
+	}{
+		{"from header", "req-42", false},
+		{"missing", "", true},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), true},
+		{"control characters", "req\n42", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var requestID string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID = context_helper.RequestID(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(RequestIDHeader, tt.header)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+
+			assert.Equal(t, requestID, recorder.Header().Get(RequestIDHeader))
+			if tt.generated {
+				_, err := uuid.Parse(requestID)
+				require.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.header, requestID)
+			}
+		})
+	}
+}
+
+func Test_RequestLoggerRequestID(t *testing.T) {
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name    string
+		handler func(logger *slog.Logger) http.Handler
+	}{
+		{"request id outside", func(logger *slog.Logger) http.Handler {
+			return RequestID(RequestLogger(logger)(ok))
+		}},
+		{"request id inside", func(logger *slog.Logger) http.Handler {
+			return RequestLogger(logger)(RequestID(ok))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			logger, records := logtest.NewLogger()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(RequestIDHeader, "req-42")
+			tt.handler(logger).ServeHTTP(httptest.NewRecorder(), r)
+
+			logtest.AssertLogged(t, records, slog.LevelInfo, "request accepted", slog.String(log.AttrRequestID, "req-42"))
+		})
+	}
+}
+
+func Test_RequestLoggerNoRequestID(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+	RequestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Len(t, records.Records(), 1)
+	_, ok := records.Records()[0].Attr(log.AttrRequestID)
+	assert.False(t, ok)
+}
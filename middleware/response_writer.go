@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseWriter wraps http.ResponseWriter to record the status code, the number
+// of bytes written and the time to the first byte. It passes http.Flusher, http.Hijacker
+// and io.ReaderFrom through, and implements Unwrap for http.ResponseController.
+type ResponseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+	bytes       int64
+	start       time.Time
+	firstByte   time.Duration
+}
+
+var (
+	_ http.Flusher  = (*ResponseWriter)(nil)
+	_ http.Hijacker = (*ResponseWriter)(nil)
+	_ io.ReaderFrom = (*ResponseWriter)(nil)
+)
+
+// NewResponseWriter wraps w, or returns it if it is already a *ResponseWriter,
+// so that middlewares share one wrapper.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+		start:          time.Now(),
+	}
+}
+
+// Status returns the status code of the response, 200 if it has not been written yet.
+func (rw *ResponseWriter) Status() int {
+	return rw.status
+}
+
+// Written reports whether the response has been started, after which the status cannot change.
+func (rw *ResponseWriter) Written() bool {
+	return rw.wroteHeader
+}
+
+// BytesWritten returns the number of bytes of the body written.
+func (rw *ResponseWriter) BytesWritten() int64 {
+	return rw.bytes
+}
+
+// TimeToFirstByte returns the time from wrapping to the start of the response,
+// zero if it has not been started.
+func (rw *ResponseWriter) TimeToFirstByte() time.Duration {
+	return rw.firstByte
+}
+
+func (rw *ResponseWriter) WriteHeader(code int) {
+	// informational responses are followed by the final one
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		rw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.commit()
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *ResponseWriter) Write(p []byte) (int, error) {
+	rw.commit()
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// WriteString hides WriteString of the underlying writer, which would skip the counting.
+func (rw *ResponseWriter) WriteString(s string) (int, error) {
+	rw.commit()
+	n, err := io.WriteString(rw.ResponseWriter, s)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// ReadFrom lets the server send files with sendfile when the underlying writer can.
+func (rw *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	rw.commit()
+
+	if readerFrom, ok := rw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err := readerFrom.ReadFrom(src)
+		rw.bytes += n
+		return n, err
+	}
+
+	// hide ReadFrom of the wrapper from io.Copy
+	return io.Copy(struct{ io.Writer }{rw}, src)
+}
+
+// Flush sends buffered data to the client, it does nothing if the underlying writer cannot.
+func (rw *ResponseWriter) Flush() {
+	rw.commit()
+	http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection, it returns an error wrapping http.ErrNotSupported
+// if the underlying writer cannot.
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *ResponseWriter) commit() {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.firstByte = time.Since(rw.start)
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_ResponseWriter(t *testing.T) {
+
+	recorder := httptest.NewRecorder()
+	rw := NewResponseWriter(recorder)
+
+	assert.Equal(t, http.StatusOK, rw.Status())
+	assert.False(t, rw.Written())
+	assert.Zero(t, rw.TimeToFirstByte())
+
+	time.Sleep(time.Millisecond)
+	rw.WriteHeader(http.StatusCreated)
+	rw.WriteHeader(http.StatusInternalServerError)
+	rw.Write([]byte("hello "))
+	io.WriteString(rw, "world")
+
+	assert.Equal(t, http.StatusCreated, rw.Status())
+	assert.True(t, rw.Written())
+	assert.Equal(t, int64(11), rw.BytesWritten())
+	assert.GreaterOrEqual(t, rw.TimeToFirstByte(), time.Millisecond)
+	assert.Equal(t, "hello world", recorder.Body.String())
+
+	assert.Same(t, rw, NewResponseWriter(rw))
+}
+
+// codesWriter records status codes, httptest.ResponseRecorder treats 1xx codes as final.
+type codesWriter struct {
+	http.ResponseWriter
+	codes []int
+}
+
+func (w *codesWriter) WriteHeader(code int) {
+	w.codes = append(w.codes, code)
+}
+
+func Test_ResponseWriterInformational(t *testing.T) {
+
+	w := &codesWriter{ResponseWriter: httptest.NewRecorder()}
+	rw := NewResponseWriter(w)
+
+	rw.WriteHeader(http.StatusEarlyHints)
+	assert.False(t, rw.Written())
+
+	rw.WriteHeader(http.StatusNoContent)
+	assert.True(t, rw.Written())
+	assert.Equal(t, http.StatusNoContent, rw.Status())
+	assert.Equal(t, []int{http.StatusEarlyHints, http.StatusNoContent}, w.codes)
+}
+
+func Test_ResponseWriterFlush(t *testing.T) {
+
+	recorder := httptest.NewRecorder()
+	rw := NewResponseWriter(recorder)
+
+	require.NoError(t, http.NewResponseController(rw).Flush())
+	assert.True(t, recorder.Flushed)
+	assert.True(t, rw.Written())
+}
+
+func Test_ResponseWriterReadFrom(t *testing.T) {
+
+	recorder := httptest.NewRecorder()
+	rw := NewResponseWriter(recorder)
+
+	n, err := rw.ReadFrom(strings.NewReader("file content"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), n)
+	assert.Equal(t, int64(12), rw.BytesWritten())
+	assert.Equal(t, "file content", recorder.Body.String())
+}
+
+func Test_ResponseWriterHijack(t *testing.T) {
+
+	_, _, err := NewResponseWriter(httptest.NewRecorder()).Hijack()
+	assert.True(t, errors.Is(err, http.ErrNotSupported))
+
+	logger, _ := logtest.NewLogger()
+	server := httptest.NewServer(RequestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\n\r\nhijacked")
+		buf.Flush()
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hijacked", string(body))
+}
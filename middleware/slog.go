@@ -1,63 +1,272 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/vishenosik/web/api"
+	context_helper "github.com/vishenosik/web/context"
 	attrs "github.com/vishenosik/web/log"
 )
 
-type loggingResponseWriter struct {
-	http.ResponseWriter
-	statusCode int
+const (
+	TraceParentHeader = "traceparent"
+)
+
+const (
+	AttrClientIP        = "client_ip"
+	AttrUserAgent       = "user_agent"
+	AttrQuery           = "query"
+	AttrRoute           = "route"
+	AttrBytes           = "bytes"
+	AttrRequestHeaders  = "request_headers"
+	AttrResponseHeaders = "response_headers"
+	AttrSlow            = "slow"
+)
+
+// RequestField is an optional field of RequestLogger lines.
+type RequestField uint8
+
+const (
+	// FieldClientIP is the host of the remote address of the request.
+	FieldClientIP RequestField = 1 << iota
+	// FieldUserAgent is the User-Agent header.
+	FieldUserAgent
+	// FieldQuery is the raw query of the URL.
+	FieldQuery
+	// FieldRoute is the pattern of the ServeMux route the request matched,
+	// set if RequestLogger wraps the ServeMux directly.
+	FieldRoute
+	// FieldBytes is the number of bytes of the response body.
+	FieldBytes
+)
+
+type requestLoggerOptions struct {
+	trace           attrs.TraceExtractor
+	fields          RequestField
+	requestHeaders  []string
+	responseHeaders []string
+	skipPaths       map[string]struct{}
+	level           func(status int) slog.Level
+	slowThreshold   time.Duration
+}
+
+// The signature of the function for setting RequestLogger parameters
+type RequestLoggerOption func(*requestLoggerOptions)
+
+// WithTraceExtractor sets how RequestLogger reads the trace of a request,
+// by default it reads the W3C traceparent header.
+func WithTraceExtractor(extractor attrs.TraceExtractor) RequestLoggerOption {
+	return func(opts *requestLoggerOptions) {
+		opts.trace = extractor
+	}
+}
+
+// WithFields adds optional fields to every line.
+func WithFields(fields ...RequestField) RequestLoggerOption {
+	return func(opts *requestLoggerOptions) {
+		for _, field := range fields {
+			opts.fields |= field
+		}
+	}
+}
+
+// WithRequestHeaders logs the request headers of the names, if they are set.
+func WithRequestHeaders(names ...string) RequestLoggerOption {
+	return func(opts *requestLoggerOptions) {
+		opts.requestHeaders = append(opts.requestHeaders, names...)
+	}
 }
 
-func newLoggingResponseWriter(w http.ResponseWriter) *loggingResponseWriter {
-	return &loggingResponseWriter{w, http.StatusOK}
+// WithResponseHeaders logs the response headers of the names, if they are set.
+func WithResponseHeaders(names ...string) RequestLoggerOption {
+	return func(opts *requestLoggerOptions) {
+		opts.responseHeaders = append(opts.responseHeaders, names...)
+	}
+}
+
+// WithSkipPaths does not log requests of the paths, like health checks.
+func WithSkipPaths(paths ...string) RequestLoggerOption {
+	return func(opts *requestLoggerOptions) {
+		for _, path := range paths {
+			opts.skipPaths[path] = struct{}{}
+		}
+	}
+}
+
+// WithStatusLevel sets the level of a line by the status code, DefaultStatusLevel by default.
+func WithStatusLevel(level func(status int) slog.Level) RequestLoggerOption {
+	return func(opts *requestLoggerOptions) {
+		if level != nil {
+			opts.level = level
+		}
+	}
 }
 
-func (lrw *loggingResponseWriter) WriteHeader(code int) {
-	lrw.statusCode = code
-	lrw.ResponseWriter.WriteHeader(code)
+// WithSlowThreshold logs requests taking at least threshold at the warn level
+// or above and marks them as slow.
+func WithSlowThreshold(threshold time.Duration) RequestLoggerOption {
+	return func(opts *requestLoggerOptions) {
+		opts.slowThreshold = threshold
+	}
 }
 
-func RequestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
+// DefaultStatusLevel logs client and server errors at the error level,
+// redirects at the warn level and the rest at the info level.
+func DefaultStatusLevel(status int) slog.Level {
+	switch {
+	case api.IsClientError(status) || api.IsServerError(status):
+		return slog.LevelError
+	case api.IsRedirect(status):
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// RequestLogger logs a line for every request with its method, path, status code,
+// request ID, trace and duration, plus the fields set by the options.
+func RequestLogger(logger *slog.Logger, opts ...RequestLoggerOption) func(next http.Handler) http.Handler {
+
+	options := &requestLoggerOptions{
+		trace:     attrs.W3CTraceExtractor,
+		skipPaths: make(map[string]struct{}),
+		level:     DefaultStatusLevel,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 
+			if _, ok := options.skipPaths[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			timeStart := time.Now()
-			lrw := newLoggingResponseWriter(w)
+			rw := NewResponseWriter(w)
+
+			if traceParent := r.Header.Get(TraceParentHeader); traceParent != "" {
+				if ctx, err := context_helper.WithTraceParent(r.Context(), traceParent); err == nil {
+					r = r.WithContext(ctx)
+				}
+			}
 
 			log := logger.With(
 				slog.String("method", fmt.Sprintf("%s %s", r.Method, r.URL.Path)),
 			)
 
+			if traceAttrs := attrs.TraceAttrs(r.Context(), options.trace); len(traceAttrs) > 0 {
+				log = log.With(anys(traceAttrs)...)
+			}
+
 			defer func() {
-				if api.IsClientError(lrw.statusCode) || api.IsServerError(lrw.statusCode) {
-					log.Error("request failed with error",
-						slog.Int("code", lrw.statusCode),
-						//TODO slog.String("RequestID", attrs.RequestID(r)),
-						attrs.Took(timeStart),
-					)
-				} else if api.IsRedirect(lrw.statusCode) {
-					log.Warn("request redirected",
-						slog.Int("code", lrw.statusCode),
-						//TODO slog.String("RequestID", attrs.RequestID(r)),
-						attrs.Took(timeStart),
-					)
-				} else {
-					logger.Info("request accepted",
-						slog.Int("code", lrw.statusCode),
-						//TODO slog.String("RequestID", attrs.RequestID(r)),
-						attrs.Took(timeStart),
-					)
+				status := rw.Status()
+				level := options.level(status)
+
+				lineAttrs := []slog.Attr{
+					slog.Int("code", status),
+					requestIDAttr(r, rw),
+					attrs.Took(timeStart),
 				}
+				lineAttrs = append(lineAttrs, options.fieldAttrs(r, rw)...)
+
+				if options.slowThreshold > 0 && time.Since(timeStart) >= options.slowThreshold {
+					lineAttrs = append(lineAttrs, slog.Bool(AttrSlow, true))
+					level = max(level, slog.LevelWarn)
+				}
+
+				log.LogAttrs(context.Background(), level, requestMessage(status), lineAttrs...)
 			}()
-			next.ServeHTTP(lrw, r)
+			next.ServeHTTP(rw, r)
 		}
 		return http.HandlerFunc(fn)
 	}
 }
+
+func requestMessage(status int) string {
+	switch {
+	case api.IsClientError(status) || api.IsServerError(status):
+		return "request failed with error"
+	case api.IsRedirect(status):
+		return "request redirected"
+	}
+	return "request accepted"
+}
+
+// fieldAttrs returns attributes of the optional fields.
+func (opts *requestLoggerOptions) fieldAttrs(r *http.Request, rw *ResponseWriter) []slog.Attr {
+	var out []slog.Attr
+
+	if opts.fields&FieldClientIP != 0 {
+		out = append(out, slog.String(AttrClientIP, clientIP(r)))
+	}
+	if opts.fields&FieldUserAgent != 0 {
+		out = append(out, slog.String(AttrUserAgent, r.UserAgent()))
+	}
+	if opts.fields&FieldQuery != 0 && r.URL.RawQuery != "" {
+		out = append(out, slog.String(AttrQuery, r.URL.RawQuery))
+	}
+	if opts.fields&FieldRoute != 0 && r.Pattern != "" {
+		out = append(out, slog.String(AttrRoute, r.Pattern))
+	}
+	if opts.fields&FieldBytes != 0 {
+		out = append(out, slog.Int64(AttrBytes, rw.BytesWritten()))
+	}
+	if headers := headerAttrs(r.Header, opts.requestHeaders); len(headers) > 0 {
+		out = append(out, slog.Attr{Key: AttrRequestHeaders, Value: slog.GroupValue(headers...)})
+	}
+	if headers := headerAttrs(rw.Header(), opts.responseHeaders); len(headers) > 0 {
+		out = append(out, slog.Attr{Key: AttrResponseHeaders, Value: slog.GroupValue(headers...)})
+	}
+
+	return out
+}
+
+func headerAttrs(header http.Header, names []string) []slog.Attr {
+	var out []slog.Attr
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			out = append(out, slog.String(http.CanonicalHeaderKey(name), strings.Join(values, ", ")))
+		}
+	}
+	return out
+}
+
+// clientIP returns the host of the remote address. Behind a proxy the address
+// is the proxy's one, unless a middleware before sets it from forwarding headers.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestIDAttr returns the request ID set by the RequestID middleware,
+// read from the response header if the middleware runs after RequestLogger.
+// The attribute is empty and left out by handlers if there is no ID.
+func requestIDAttr(r *http.Request, w http.ResponseWriter) slog.Attr {
+	requestID := context_helper.RequestID(r.Context())
+	if requestID == "" {
+		requestID = w.Header().Get(RequestIDHeader)
+	}
+	if requestID == "" {
+		return slog.Attr{}
+	}
+	return attrs.RequestID(requestID)
+}
+
+func anys(list []slog.Attr) []any {
+	out := make([]any, len(list))
+	for i := range list {
+		out[i] = list[i]
+	}
+	return out
+}
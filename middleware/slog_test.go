@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	context_helper "github.com/vishenosik/web/context"
+	"github.com/vishenosik/web/log"
+	"github.com/vishenosik/web/log/logtest"
+)
+
+func Test_RequestLogger(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		code  int
+		level slog.Level
+		msg   string
+	}{
+		{"accepted", http.StatusOK, slog.LevelInfo, "request accepted"},
+		{"redirected", http.StatusFound, slog.LevelWarn, "request redirected"},
+		{"client error", http.StatusNotFound, slog.LevelError, "request failed with error"},
+		{"server error", http.StatusInternalServerError, slog.LevelError, "request failed with error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			logger, records := logtest.NewLogger()
+
+			handler := RequestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.code)
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+			require.Len(t, records.Records(), 1)
+			logtest.AssertLogged(t, records, tt.level, tt.msg, slog.Int("code", tt.code))
+		})
+	}
+}
+
+func Test_RequestLoggerTrace(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	var traceID string
+	handler := RequestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceCtx, ok := context_helper.TraceCtx(r.Context())
+		require.True(t, ok)
+		traceID = traceCtx.TraceID()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	logtest.AssertLogged(t, records, slog.LevelError, "request failed with error",
+		slog.String(log.AttrTraceID, traceID),
+		slog.String(log.AttrSpanID, "00f067aa0ba902b7"),
+	)
+}
+
+func Test_RequestLoggerTraceExtractor(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	extractor := log.TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+		return "custom-trace", "", true
+	})
+
+	handler := RequestLogger(logger, WithTraceExtractor(extractor))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	record := records.Records()[0]
+	traceID, _ := record.Attr(log.AttrTraceID)
+	assert.Equal(t, "custom-trace", traceID.String())
+	_, ok := record.Attr(log.AttrSpanID)
+	assert.False(t, ok)
+}
+
+func Test_RequestLoggerFields(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"id":1}`))
+	})
+
+	handler := RequestLogger(logger,
+		WithFields(FieldClientIP, FieldUserAgent, FieldQuery),
+		WithFields(FieldRoute, FieldBytes),
+		WithRequestHeaders("accept", "X-Missing"),
+		WithResponseHeaders("Content-Type"),
+	)(mux)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users/1?fields=name", nil)
+	r.RemoteAddr = "192.0.2.1:52000"
+	r.Header.Set("User-Agent", "curl/8.0")
+	r.Header.Set("Accept", "application/json")
+	r.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	logtest.AssertLogged(t, records, slog.LevelInfo, "request accepted",
+		slog.String("method", "GET /api/v1/users/1"),
+		slog.String(AttrClientIP, "192.0.2.1"),
+		slog.String(AttrUserAgent, "curl/8.0"),
+		slog.String(AttrQuery, "fields=name"),
+		slog.String(AttrRoute, "GET /api/v1/users/{id}"),
+		slog.Int64(AttrBytes, 8),
+		slog.Group(AttrRequestHeaders, slog.String("Accept", "application/json")),
+		slog.Group(AttrResponseHeaders, slog.String("Content-Type", "application/json")),
+	)
+
+	record := records.Records()[0]
+	_, ok := record.Attr(AttrRequestHeaders, "Authorization")
+	assert.False(t, ok)
+	_, ok = record.Attr(AttrResponseHeaders, "Set-Cookie")
+	assert.False(t, ok)
+}
+
+func Test_RequestLoggerSkipPaths(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+	handler := RequestLogger(logger, WithSkipPaths("/healthz"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz/deep", nil))
+
+	logtest.AssertMessages(t, records, "request accepted")
+}
+
+func Test_RequestLoggerStatusLevel(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	level := func(status int) slog.Level {
+		if status == http.StatusNotFound {
+			return slog.LevelDebug
+		}
+		return DefaultStatusLevel(status)
+	}
+
+	handler := RequestLogger(logger, WithStatusLevel(level))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	logtest.AssertLogged(t, records, slog.LevelDebug, "request failed with error", slog.Int("code", http.StatusNotFound))
+}
+
+func Test_RequestLoggerSlow(t *testing.T) {
+
+	logger, records := logtest.NewLogger()
+
+	handler := RequestLogger(logger, WithSlowThreshold(time.Millisecond))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	logtest.AssertLogged(t, records, slog.LevelWarn, "request accepted",
+		slog.String("method", "GET /slow"),
+		slog.Bool(AttrSlow, true),
+	)
+}